@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CatalogItem is a single product from a vendor catalog
+type CatalogItem struct {
+	SKU    string
+	Name   string
+	URL    string
+	Vendor string // Vendor that the catalog file is for
+	Source string // File that the item was loaded from
+}
+
+// Catalog holds all of the products loaded with the -catalog option
+type Catalog struct {
+	order   []string // SKU keys in the order they were loaded
+	bySKU   map[string]CatalogItem
+	byName  map[string]CatalogItem
+	vendors map[string]bool // Vendors which have a catalog (lower case)
+}
+
+// catalogKey makes the lookup key for a SKU or name of a vendor
+func catalogKey(vendor string, key string) string {
+	return strings.ToLower(strings.TrimSpace(vendor)) + "`" + key
+}
+
+// catalogSource splits a -catalog value of the form Vendor=file.csv.
+// Without a vendor, the name of the file (less the extension) is used as the vendor
+func catalogSource(value string) (vendor string, filename string) {
+	if pos := strings.Index(value, "="); pos >= 0 {
+		return strings.TrimSpace(value[:pos]), value[pos+1:]
+	}
+	base := filepath.Base(value)
+	return strings.TrimSuffix(base, filepath.Ext(base)), value
+}
+
+// catalogColumns figures out which columns hold the SKU, name and URL from the header row.
+// If the first row doesn't look like a header, the columns are assumed to be SKU, name, URL in that order
+func catalogColumns(row []string) (skuCol int, nameCol int, urlCol int, isHeader bool) {
+	skuCol, nameCol, urlCol = -1, -1, -1
+	for idx, title := range row {
+		switch strings.ToLower(strings.TrimSpace(title)) {
+		case "sku", "part number", "part #", "product sku":
+			skuCol = idx
+		case "name", "product name", "product", "title":
+			nameCol = idx
+		case "url", "product url", "link", "product link":
+			urlCol = idx
+		}
+	}
+	if skuCol < 0 {
+		return 0, 1, 2, false
+	}
+	return skuCol, nameCol, urlCol, true
+}
+
+// csvColumn safely gets a column from a CSV row
+func csvColumn(row []string, col int) string {
+	if col < 0 || col >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[col])
+}
+
+// LoadCatalog reads the vendor catalog CSV files given as Vendor=file.csv.
+// Later files override earlier ones for the same vendor and SKU
+func LoadCatalog(sources []string) (*Catalog, error) {
+	catalog := &Catalog{bySKU: map[string]CatalogItem{}, byName: map[string]CatalogItem{}, vendors: map[string]bool{}}
+	for _, source := range sources {
+		vendor, filename := catalogSource(source)
+		if vendor == "" {
+			return catalog, fmt.Errorf("%v: no vendor given for the catalog", source)
+		}
+		catalog.vendors[strings.ToLower(vendor)] = true
+		file, err := os.Open(filename)
+		if err != nil {
+			return catalog, err
+		}
+		reader := csv.NewReader(file)
+		reader.FieldsPerRecord = -1
+		skuCol, nameCol, urlCol := 0, 1, 2
+		first := true
+		for {
+			row, err := reader.Read()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				file.Close()
+				return catalog, fmt.Errorf("%v: %v", filename, err)
+			}
+			if first {
+				first = false
+				var isHeader bool
+				skuCol, nameCol, urlCol, isHeader = catalogColumns(row)
+				if isHeader {
+					continue
+				}
+			}
+			item := CatalogItem{
+				SKU:    csvColumn(row, skuCol),
+				Name:   csvColumn(row, nameCol),
+				URL:    csvColumn(row, urlCol),
+				Vendor: vendor,
+				Source: filename,
+			}
+			if item.SKU == "" {
+				continue
+			}
+			key := catalogKey(vendor, nearSKU(item.SKU))
+			if _, found := catalog.bySKU[key]; !found {
+				catalog.order = append(catalog.order, key)
+			}
+			catalog.bySKU[key] = item
+			if item.Name != "" {
+				catalog.byName[catalogKey(vendor, nearName(item.Name))] = item
+			}
+		}
+		file.Close()
+	}
+	return catalog, nil
+}
+
+// HasVendor determines if a catalog was loaded for the vendor
+func (c *Catalog) HasVendor(vendor string) bool {
+	return c.vendors[strings.ToLower(strings.TrimSpace(vendor))]
+}
+
+// FindSKU looks up a product of a vendor by SKU, ignoring whitespace, dash and case differences
+func (c *Catalog) FindSKU(vendor string, sku string) (CatalogItem, bool) {
+	item, found := c.bySKU[catalogKey(vendor, nearSKU(sku))]
+	return item, found
+}
+
+// FindName looks up a product of a vendor by name, ignoring whitespace, pack size and case differences
+func (c *Catalog) FindName(vendor string, name string) (CatalogItem, bool) {
+	item, found := c.byName[catalogKey(vendor, nearName(name))]
+	return item, found
+}
+
+// writeUnmodeled outputs the section of the report listing catalog items which no document claimed
+func (c *Catalog) writeUnmodeled(outfile io.Writer, seen map[string]bool) {
+	fmt.Fprintf(outfile, "\nCatalog items with no Onshape model\n")
+	fmt.Fprintf(outfile, "%v\n", strings.Join([]string{
+		"Vendor",
+		"SKU",
+		"Name",
+		"URL",
+		"Catalog"}, "`"))
+	for _, key := range c.order {
+		if !seen[key] {
+			item := c.bySKU[key]
+			fmt.Fprintf(outfile, "%v`%v`%v`%v`%v\n", item.Vendor, item.SKU, item.Name, item.URL, item.Source)
+		}
+	}
+}
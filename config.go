@@ -0,0 +1,339 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// DrawingPolicy controls the checks on the Drawing tabs in a document.
+// They are all off unless the configuration file turns them on
+type DrawingPolicy struct {
+	Required         bool `json:"required"`         // The main part or assembly must have a drawing
+	MatchName        bool `json:"matchName"`        // The drawing must have the same name as the document
+	RequireDrawnBy   bool `json:"requireDrawnBy"`   // The Drawn by property must be filled in
+	RequireDateDrawn bool `json:"requireDateDrawn"` // The Date drawn property must be filled in
+	RequireRevision  bool `json:"requireRevision"`  // The Revision property must be filled in
+}
+
+// TabPolicy controls the checks on the non-geometry tabs (Feature Studios, BLOBs, Applications, Tables and BOMs)
+type TabPolicy struct {
+	ForbidFeatureStudios    bool     `json:"forbidFeatureStudios"`    // Library documents must not contain Feature Studios
+	ForbidApplications      bool     `json:"forbidApplications"`      // Library documents must not contain Application tabs
+	BlobExtensions          []string `json:"blobExtensions"`          // Allowed file extensions for BLOB tabs (empty allows any)
+	BlobNamePattern         string   `json:"blobNamePattern"`         // Regular expression that BLOB tab names must match (empty allows any)
+	CheckUnconvertedImports bool     `json:"checkUnconvertedImports"` // Report imported CAD files with no matching Part Studio or Assembly
+	ImportExtensions        []string `json:"importExtensions"`        // File extensions which are CAD files that Onshape can translate
+
+	blobNameRegexp *regexp.Regexp
+}
+
+// MaterialPolicy controls the material checks which apply to every part
+type MaterialPolicy struct {
+	Required bool `json:"required"` // Every main part must have a material assigned
+}
+
+// DescriptionPolicy controls how document descriptions are parsed and checked
+type DescriptionPolicy struct {
+	Layouts    []DescriptionLayout `json:"layouts"`    // Accepted arrangements of the description, tried in order
+	RequireURL bool                `json:"requireUrl"` // The description must have a product URL
+}
+
+// LifecyclePolicy controls the checks on obsolete and discontinued documents
+type LifecyclePolicy struct {
+	RequireReplacement   bool   `json:"requireReplacement"`   // Must have a [REPLACED BY ...] tag naming the replacement
+	ArchiveFolder        string `json:"archiveFolder"`        // Folder path that retired documents must be moved to (empty to skip)
+	RequireObsoleteState bool   `json:"requireObsoleteState"` // The main part or assembly must have its State set to Obsolete
+}
+
+// ReleasePolicy controls the checks on the release State of parts and assemblies
+type ReleasePolicy struct {
+	RequireReleased          bool `json:"requireReleased"`          // The main part or assembly of an active document must be Released
+	HelperNotRevisionManaged bool `json:"helperNotRevisionManaged"` // Parts in the helper PARTS studios must be Not revision managed
+}
+
+// PackPolicy controls the checks for hardware which is sold in packs such as "Socket Head Screw - 25 Pack"
+type PackPolicy struct {
+	Unit             string `json:"unit"`             // Unit of measure the BOM should use for a pack (empty to skip)
+	QuantityProperty string `json:"quantityProperty"` // Name of the custom property holding the number in a pack
+	RequireQuantity  bool   `json:"requireQuantity"`  // Documents with a pack size in the name must set the quantity property
+}
+
+// The kinds of values that a company property can be checked for
+const (
+	propertyString  = "string"
+	propertyNumber  = "number"
+	propertyInteger = "integer"
+	propertyBool    = "bool"
+	propertyDate    = "date"
+)
+
+// PropertyField describes a company specific property of the main part or assembly such as "Vendor SKU" or "Price"
+type PropertyField struct {
+	Name     string `json:"name"`     // Name of the property in Onshape
+	ID       string `json:"id"`       // Property ID to use instead of the name (names can be duplicated across companies)
+	Column   string `json:"column"`   // Report column for the value (defaults to the name)
+	Type     string `json:"type"`     // string, number, integer, bool or date (defaults to string)
+	Required bool   `json:"required"` // The property must have a value
+	Pattern  string `json:"pattern"`  // Regular expression which the value must match (empty for any value)
+
+	patternRegexp *regexp.Regexp
+}
+
+// column gives the name of the report column for the property
+func (p PropertyField) column() string {
+	if p.Column != "" {
+		return p.Column
+	}
+	if p.Name != "" {
+		return p.Name
+	}
+	return p.ID
+}
+
+// MassPolicy controls the sanity checks on the mass properties of main parts (only used with -massprops)
+type MassPolicy struct {
+	MaxSize          float64 `json:"maxSize"`          // Largest allowed bounding box side in meters (0 for no limit)
+	MaxMass          float64 `json:"maxMass"`          // Largest allowed mass in kg (0 for no limit)
+	DensityTolerance float64 `json:"densityTolerance"` // Allowed fractional difference between the part density and the material
+}
+
+// RewriteRule is a single rewrite that is tried when a name doesn't match the document name.
+// If the rewritten name matches then the name can be fixed automatically and the Reason is reported
+type RewriteRule struct {
+	Pattern string `json:"pattern"` // Regular expression to look for
+	Replace string `json:"replace"` // What to replace it with (may use $1 style references)
+	Reason  string `json:"reason"`  // Explanation of the fix for the report
+	Pack    bool   `json:"pack"`    // The rule strips pack size markers, so it is also used to find the pack size
+
+	patternRegexp *regexp.Regexp
+}
+
+// MaterialRule requires parts whose name matches the pattern to use one of the listed materials
+type MaterialRule struct {
+	NamePattern string   `json:"namePattern"` // Regular expression for the part name (empty matches all parts)
+	Materials   []string `json:"materials"`   // Allowed material names
+
+	nameRegexp *regexp.Regexp
+}
+
+// VendorPolicy holds the settings that are specific to a single vendor
+type VendorPolicy struct {
+	Name      string         `json:"name"`      // Vendor name exactly as it should appear in the Vendor property
+	Folders   []string       `json:"folders"`   // Folder path prefixes that hold the documents for the vendor
+	Materials []MaterialRule `json:"materials"` // Materials that the vendor's parts must use
+	// Purchased parts should have a real color assigned rather than the one Onshape generates
+	ForbidGeneratedColors bool `json:"forbidGeneratedColors"`
+	// Colors (as #rrggbb) that the vendor's parts may use.  Empty allows any color
+	Palette []string `json:"palette"`
+	// Regular expression that the Part number must match such as ^\d{4}-\d{4}-\d{4}$ (empty allows any)
+	SKUPattern string `json:"skuPattern"`
+	// Web site domains for the vendor such as gobilda.com.  Product URLs must be on one of them (empty allows any)
+	Domains []string `json:"domains"`
+	// Product URLs must contain the Part number
+	URLContainsSKU bool `json:"urlContainsSku"`
+
+	skuRegexp *regexp.Regexp
+}
+
+// AuditConfig holds all of the policy settings which control what we check for.
+// It is loaded from the JSON file named by the -config option
+type AuditConfig struct {
+	Drawings     DrawingPolicy     `json:"drawings"`
+	Descriptions DescriptionPolicy `json:"descriptions"`
+	Lifecycle    LifecyclePolicy   `json:"lifecycle"`
+	Release      ReleasePolicy     `json:"release"`
+	Packs        PackPolicy        `json:"packs"`
+	Tabs         TabPolicy         `json:"tabs"`
+	Materials    MaterialPolicy    `json:"materials"`
+	Mass         MassPolicy        `json:"mass"`
+	NameRewrites []RewriteRule     `json:"nameRewrites"`
+	Properties   []PropertyField   `json:"properties"`
+	Vendors      []VendorPolicy    `json:"vendors"`
+}
+
+// defaultAuditConfig gives the policy that is used for anything not set in the configuration file
+func defaultAuditConfig() AuditConfig {
+	return AuditConfig{
+		Descriptions: DescriptionPolicy{
+			Layouts: []DescriptionLayout{
+				{Name: "standard", Sections: []string{"name", "url?", "tags", "notes"}},
+				{Name: "tags first", Sections: []string{"tags", "name", "url?", "notes"}},
+			},
+			RequireURL: true,
+		},
+		Lifecycle: LifecyclePolicy{
+			RequireReplacement: true,
+		},
+		Packs: PackPolicy{
+			Unit:             "Each",
+			QuantityProperty: "Pack quantity",
+		},
+		Mass: MassPolicy{
+			DensityTolerance: 0.1,
+		},
+		Tabs: TabPolicy{
+			ImportExtensions: []string{".step", ".stp", ".iges", ".igs", ".x_t", ".x_b", ".sldprt", ".sldasm", ".stl", ".ipt", ".iam", ".f3d"},
+		},
+		NameRewrites: []RewriteRule{
+			{Pattern: defaultPackPattern, Replace: "", Reason: "pack size", Pack: true},
+			{Pattern: `[“”″]`, Replace: `"`, Reason: "smart quotes"},
+			{Pattern: `[‘’′]`, Replace: "'", Reason: "smart quotes"},
+			{Pattern: `\s{2,}`, Replace: " ", Reason: "extra spaces"},
+			{Pattern: `[\s.,;:]+$`, Replace: "", Reason: "trailing punctuation"},
+		},
+	}
+}
+
+// compile prepares all of the regular expressions in the configuration so that they are checked once up front
+func (c *AuditConfig) compile() error {
+	var err error
+	for _, layout := range c.Descriptions.Layouts {
+		err = validateLayout(layout)
+		if err != nil {
+			return fmt.Errorf("descriptions.layouts: %v", err)
+		}
+	}
+	if c.Tabs.BlobNamePattern != "" {
+		c.Tabs.blobNameRegexp, err = regexp.Compile(c.Tabs.BlobNamePattern)
+		if err != nil {
+			return fmt.Errorf("tabs.blobNamePattern: %v", err)
+		}
+	}
+	for ridx := range c.NameRewrites {
+		rule := &c.NameRewrites[ridx]
+		rule.patternRegexp, err = regexp.Compile(rule.Pattern)
+		if err != nil {
+			return fmt.Errorf("nameRewrites[%v].pattern: %v", ridx, err)
+		}
+	}
+	// Each property needs its own column or the values would overwrite each other
+	columns := map[string]bool{}
+	for _, column := range standardColumns() {
+		columns[strings.ToLower(column)] = true
+	}
+	for pidx := range c.Properties {
+		property := &c.Properties[pidx]
+		if property.Name == "" && property.ID == "" {
+			return fmt.Errorf("properties[%v]: a name or id is required", pidx)
+		}
+		if columns[strings.ToLower(property.column())] {
+			return fmt.Errorf("properties[%v]: column '%v' is already used", pidx, property.column())
+		}
+		columns[strings.ToLower(property.column())] = true
+		switch property.Type {
+		case "":
+			property.Type = propertyString
+		case propertyString, propertyNumber, propertyInteger, propertyBool, propertyDate:
+		default:
+			return fmt.Errorf("properties[%v].type: unknown type '%v'", property.column(), property.Type)
+		}
+		if property.Pattern != "" {
+			property.patternRegexp, err = regexp.Compile(property.Pattern)
+			if err != nil {
+				return fmt.Errorf("properties[%v].pattern: %v", property.column(), err)
+			}
+		}
+	}
+	for vidx := range c.Vendors {
+		vendor := &c.Vendors[vidx]
+		if vendor.SKUPattern != "" {
+			vendor.skuRegexp, err = regexp.Compile(vendor.SKUPattern)
+			if err != nil {
+				return fmt.Errorf("vendors[%v].skuPattern: %v", vendor.Name, err)
+			}
+		}
+		for ridx := range vendor.Materials {
+			rule := &vendor.Materials[ridx]
+			rule.nameRegexp, err = regexp.Compile(rule.NamePattern)
+			if err != nil {
+				return fmt.Errorf("vendors[%v].materials[%v].namePattern: %v", vendor.Name, ridx, err)
+			}
+		}
+	}
+	return nil
+}
+
+// pathHasPrefix determines if a folder path is the same as or beneath the prefix path (ignoring case)
+func pathHasPrefix(path string, prefix string) bool {
+	return strings.EqualFold(path, prefix) ||
+		(len(path) > len(prefix)+3 && strings.EqualFold(path[:len(prefix)+3], prefix+" > "))
+}
+
+// vendorByFolder finds the vendor whose folder holds a document.
+// When more than one folder matches, the longest (most specific) one wins
+func (c *AuditConfig) vendorByFolder(path string) *VendorPolicy {
+	var result *VendorPolicy
+	longest := -1
+	for vidx := range c.Vendors {
+		for _, folder := range c.Vendors[vidx].Folders {
+			if len(folder) > longest && pathHasPrefix(path, folder) {
+				result = &c.Vendors[vidx]
+				longest = len(folder)
+			}
+		}
+	}
+	return result
+}
+
+// vendorByName finds the vendor with the given name (ignoring case)
+func (c *AuditConfig) vendorByName(name string) *VendorPolicy {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil
+	}
+	for vidx := range c.Vendors {
+		if strings.EqualFold(c.Vendors[vidx].Name, name) {
+			return &c.Vendors[vidx]
+		}
+	}
+	return nil
+}
+
+// vendorPolicy picks the vendor settings to use for a document, first by the Vendor property and then by the folder.
+// If neither one is known, nil is returned
+func (c *AuditConfig) vendorPolicy(path string, vendor string) *VendorPolicy {
+	result := c.vendorByName(vendor)
+	if result == nil {
+		result = c.vendorByFolder(path)
+	}
+	return result
+}
+
+// LoadAuditConfig reads the policy configuration file.
+// Anything not mentioned in the file keeps the default value.  A blank filename just uses the defaults
+func LoadAuditConfig(filename string) (AuditConfig, error) {
+	config := defaultAuditConfig()
+	if filename != "" {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return config, err
+		}
+		// encoding/json would merge the entries of a list in the file into the default entries in the
+		// same position, so the lists start out empty and only get the defaults back if the file leaves them out
+		defaults := config
+		config.NameRewrites = nil
+		config.Descriptions.Layouts = nil
+		config.Tabs.ImportExtensions = nil
+		err = json.Unmarshal(data, &config)
+		if err != nil {
+			return config, fmt.Errorf("unable to parse %v: %v", filename, err)
+		}
+		if config.NameRewrites == nil {
+			config.NameRewrites = defaults.NameRewrites
+		}
+		if config.Descriptions.Layouts == nil {
+			config.Descriptions.Layouts = defaults.Descriptions.Layouts
+		}
+		if config.Tabs.ImportExtensions == nil {
+			config.Tabs.ImportExtensions = defaults.Tabs.ImportExtensions
+		}
+	}
+	err := config.compile()
+	if err != nil {
+		return config, fmt.Errorf("%v: %v", filename, err)
+	}
+	return config, nil
+}
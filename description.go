@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// The sections which can make up a description layout
+const (
+	sectionName  = "name"  // A single line with the product name
+	sectionURL   = "url"   // A single line with the product URL
+	sectionTags  = "tags"  // Any number of lines like [OBSOLETE] or [REPLACED BY 1234-5678]
+	sectionNotes = "notes" // Everything else (other than links and tags)
+)
+
+// DescriptionLayout is one of the accepted arrangements of the lines in a document description.
+// A name or url section followed by "?" is optional.  Tags and notes may always be empty.
+type DescriptionLayout struct {
+	Name     string   `json:"name"`
+	Sections []string `json:"sections"`
+}
+
+// ParsedDescription holds the pieces of a document description
+type ParsedDescription struct {
+	Layout   string   // Name of the layout that matched (blank if none did)
+	Lines    []string // All of the lines of the description
+	Name     string
+	NameLine int // Index of the name in Lines (-1 if there is none)
+	URL      string
+	URLLine  int      // Index of the URL in Lines (-1 if there is none)
+	Tags     []string // Contents of the tag lines without the brackets
+	Notes    string
+}
+
+// validateLayout makes sure that a layout only uses sections that we know about
+func validateLayout(layout DescriptionLayout) error {
+	for _, section := range layout.Sections {
+		switch strings.TrimSuffix(section, "?") {
+		case sectionName, sectionURL, sectionTags, sectionNotes:
+		default:
+			return fmt.Errorf("layout %v has unknown section '%v'", layout.Name, section)
+		}
+	}
+	return nil
+}
+
+// isTagLine determines if a line of the description is a tag such as [OBSOLETE]
+func isTagLine(line string) bool {
+	line = strings.TrimSpace(line)
+	return len(line) > 2 && strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]")
+}
+
+// isURLLine determines if a line of the description looks like a link
+func isURLLine(line string) bool {
+	parsed, err := url.Parse(strings.TrimSpace(line))
+	return err == nil && parsed.Host != "" && (parsed.Scheme == "http" || parsed.Scheme == "https")
+}
+
+// matchLayout tries to parse the lines with a single layout
+func matchLayout(lines []string, layout DescriptionLayout) (ParsedDescription, bool) {
+	result := ParsedDescription{Layout: layout.Name, Lines: lines, NameLine: -1, URLLine: -1}
+	notes := []string{}
+	pos := 0
+	for _, section := range layout.Sections {
+		optional := strings.HasSuffix(section, "?")
+		switch strings.TrimSuffix(section, "?") {
+		case sectionName:
+			if pos < len(lines) && strings.TrimSpace(lines[pos]) != "" && !isTagLine(lines[pos]) && !isURLLine(lines[pos]) {
+				result.Name = lines[pos]
+				result.NameLine = pos
+				pos++
+			} else if !optional {
+				return result, false
+			}
+		case sectionURL:
+			if pos < len(lines) && isURLLine(lines[pos]) {
+				result.URL = lines[pos]
+				result.URLLine = pos
+				pos++
+			} else if !optional {
+				return result, false
+			}
+		case sectionTags:
+			for pos < len(lines) && isTagLine(lines[pos]) {
+				tag := strings.TrimSpace(lines[pos])
+				result.Tags = append(result.Tags, strings.TrimSpace(tag[1:len(tag)-1]))
+				pos++
+			}
+		case sectionNotes:
+			// A link or tag in the notes is out of place, so it stops the notes and keeps the layout from matching
+			for pos < len(lines) && !isURLLine(lines[pos]) && !isTagLine(lines[pos]) {
+				if strings.TrimSpace(lines[pos]) != "" {
+					notes = append(notes, strings.TrimSpace(lines[pos]))
+				}
+				pos++
+			}
+		}
+	}
+	// Everything has to be accounted for (other than trailing blank lines)
+	for pos < len(lines) {
+		if strings.TrimSpace(lines[pos]) != "" {
+			return result, false
+		}
+		pos++
+	}
+	result.Notes = strings.Join(notes, " ")
+	return result, true
+}
+
+// ParseDescription splits a document description into its pieces using the first layout that matches.
+// If none of them match, the Layout is left blank but any tag lines are still picked up
+// so that something like "[OBSOLETE]\n[REPLACED BY 1234]" is understood
+func ParseDescription(description string, layouts []DescriptionLayout) ParsedDescription {
+	lines := strings.Split(strings.ReplaceAll(description, "\r\n", "\n"), "\n")
+	for _, layout := range layouts {
+		parsed, matched := matchLayout(lines, layout)
+		if matched {
+			return parsed
+		}
+	}
+	result := ParsedDescription{Lines: lines, NameLine: -1, URLLine: -1}
+	for _, line := range lines {
+		if isTagLine(line) {
+			tag := strings.TrimSpace(line)
+			result.Tags = append(result.Tags, strings.TrimSpace(tag[1:len(tag)-1]))
+		}
+	}
+	return result
+}
+
+// Tag looks for a tag which starts with the keyword (ignoring case) such as "REPLACED BY".
+// It returns whatever follows the keyword in the tag
+func (p ParsedDescription) Tag(keyword string) (string, bool) {
+	for _, tag := range p.Tags {
+		if len(tag) >= len(keyword) && strings.EqualFold(tag[:len(keyword)], keyword) {
+			return strings.TrimSpace(tag[len(keyword):]), true
+		}
+	}
+	return "", false
+}
+
+// SetLine replaces one of the lines of the description and returns the updated description
+func (p *ParsedDescription) SetLine(line int, value string) string {
+	if line >= 0 && line < len(p.Lines) {
+		p.Lines[line] = value
+		if line == p.NameLine {
+			p.Name = value
+		} else if line == p.URLLine {
+			p.URL = value
+		}
+	}
+	return strings.Join(p.Lines, "\n")
+}
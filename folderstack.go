@@ -3,12 +3,16 @@ package main
 import (
 	"container/list"
 	"fmt"
+
+	"github.com/toebes/go-client/onshape"
 )
 
 // FolderEntry is a queued entry to track a single folder
 type FolderEntry struct {
-	FolderID   string // Name of the folder
-	FolderPath string // Path of the containing parent
+	FolderID   string                             // Name of the folder
+	FolderPath string                             // Path of the containing parent
+	ParentID   string                             // ID of the folder that contains this one (blank for a seed)
+	Node       *onshape.BTGlobalTreeMagicNodeInfo // Tree information for the folder (nil for a seed)
 }
 
 // FolderStack is used to maintain a queue of folders to process
@@ -18,8 +22,8 @@ type FolderStack struct {
 }
 
 // Push puts an entry at the top of the stack
-func (c *FolderStack) Push(value string, parentPath string) {
-	c.queue.PushFront(FolderEntry{FolderID: value, FolderPath: parentPath})
+func (c *FolderStack) Push(value string, parentPath string, parentID string, node *onshape.BTGlobalTreeMagicNodeInfo) {
+	c.queue.PushFront(FolderEntry{FolderID: value, FolderPath: parentPath, ParentID: parentID, Node: node})
 }
 
 // Pop removes the entry from the top of the stack
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// folderStats tracks what we have seen for a single folder
+type folderStats struct {
+	info          fileInfo
+	children      []string // IDs of the subfolders that we have seen
+	documents     int      // Documents directly in the folder
+	findings      int      // Findings on documents directly in the folder
+	withFindings  int      // Documents directly in the folder that have at least one finding
+	totalDocs     int      // Rolled up count of all documents beneath the folder
+	totalFindings int      // Rolled up count of all findings beneath the folder
+	totalWith     int      // Rolled up count of all documents with findings beneath the folder
+}
+
+// folderSummary accumulates the per folder statistics as the report is written.
+// It is only touched by the outputThread so it needs no locking
+type folderSummary struct {
+	order   []string // Folder IDs in the order that they were output
+	folders map[string]*folderStats
+}
+
+// getFolder finds (or creates) the entry for a folder
+func (s *folderSummary) getFolder(folderID string) *folderStats {
+	if s.folders == nil {
+		s.folders = map[string]*folderStats{}
+	}
+	stats, found := s.folders[folderID]
+	if !found {
+		stats = &folderStats{}
+		s.folders[folderID] = stats
+	}
+	return stats
+}
+
+// add records a single line of the report against the folder that contains it
+func (s *folderSummary) add(result fileInfo) {
+	if result.FolderID == "" {
+		return
+	}
+	if result.IsFolder {
+		stats := s.getFolder(result.FolderID)
+		stats.info = result
+		s.order = append(s.order, result.FolderID)
+		if result.ParentID != "" {
+			parent := s.getFolder(result.ParentID)
+			parent.children = append(parent.children, result.FolderID)
+		}
+		return
+	}
+	stats := s.getFolder(result.FolderID)
+	stats.documents++
+	stats.findings += result.CheckCount
+	if result.CheckCount > 0 {
+		stats.withFindings++
+	}
+}
+
+// rollup computes the totals for a folder and everything beneath it
+func (s *folderSummary) rollup(folderID string) *folderStats {
+	stats := s.getFolder(folderID)
+	stats.totalDocs = stats.documents
+	stats.totalFindings = stats.findings
+	stats.totalWith = stats.withFindings
+	for _, child := range stats.children {
+		childStats := s.rollup(child)
+		stats.totalDocs += childStats.totalDocs
+		stats.totalFindings += childStats.totalFindings
+		stats.totalWith += childStats.totalWith
+	}
+	return stats
+}
+
+// write outputs the folder summary as its own table with one row per folder
+func (s *folderSummary) write(outfile io.Writer) {
+	fmt.Fprintf(outfile, "%v\n", strings.Join([]string{
+		"Path",
+		"Owner",
+		"Created",
+		"Modified",
+		"Documents",
+		"Subfolders",
+		"TotalDocuments",
+		"DocumentsWithFindings",
+		"TotalFindings",
+		"OnshapeURL"}, "`"))
+	// Compute the totals from the top down starting at any folder which doesn't have a parent we saw
+	for _, folderID := range s.order {
+		stats := s.folders[folderID]
+		if _, hasParent := s.folders[stats.info.ParentID]; !hasParent {
+			s.rollup(folderID)
+		}
+	}
+	for _, folderID := range s.order {
+		stats := s.folders[folderID]
+		fmt.Fprintf(outfile, "%v`%v`%v`%v`%v`%v`%v`%v`%v`%v\n",
+			stats.info.Path,
+			stats.info.Owner,
+			stats.info.Created,
+			stats.info.Modified,
+			stats.info.Documents,
+			stats.info.Subfolders,
+			stats.totalDocs,
+			stats.totalWith,
+			stats.totalFindings,
+			stats.info.OnshapeURL)
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// libraryEntry identifies a single document in the library
+type libraryEntry struct {
+	Name       string
+	Value      string // The actual SKU or name that was indexed
+	Path       string
+	OnshapeURL string
+}
+
+// libraryGroup is a set of documents that share a key
+type libraryGroup struct {
+	key     string
+	entries []libraryEntry
+}
+
+// libraryIndex collects the SKUs and names of every document so that we can find collisions across documents.
+// It is only touched by the outputThread so it needs no locking
+type libraryIndex struct {
+	order     []string // Keys in the order first seen so that the report is stable
+	groups    map[string]*libraryGroup
+	collected int
+}
+
+// nearName reduces a name to the form used for finding near duplicates by ignoring
+// case, extra whitespace and pack size markers
+func nearName(name string) string {
+	name = stripPackSize(name)
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// nearSKU reduces a SKU to the form used for finding near duplicates
+func nearSKU(sku string) string {
+	return strings.ToUpper(normalizeSKU(sku))
+}
+
+// addKey records that a document uses the key
+func (l *libraryIndex) addKey(kind string, key string, entry libraryEntry) {
+	if key == "" {
+		return
+	}
+	if l.groups == nil {
+		l.groups = map[string]*libraryGroup{}
+	}
+	fullKey := kind + "`" + key
+	group, found := l.groups[fullKey]
+	if !found {
+		group = &libraryGroup{key: fullKey}
+		l.groups[fullKey] = group
+		l.order = append(l.order, fullKey)
+	}
+	group.entries = append(group.entries, entry)
+}
+
+// add records a single document from the report
+func (l *libraryIndex) add(result fileInfo) {
+	if result.IsFolder || result.DocumentName == "" {
+		return
+	}
+	l.collected++
+	entry := libraryEntry{Name: result.DocumentName, Value: result.DocumentName, Path: result.Path, OnshapeURL: result.OnshapeURL}
+	l.addKey("Name", strings.TrimSpace(result.DocumentName), entry)
+	l.addKey("NearName", nearName(result.DocumentName), entry)
+	// A document may legitimately report the same SKU from more than one place, so only count it once
+	skus := make([]string, 0, len(result.SKU))
+	for sku := range result.SKU {
+		skus = append(skus, sku)
+	}
+	sort.Strings(skus)
+	nearSeen := map[string]bool{}
+	for _, sku := range skus {
+		entry.Value = sku
+		l.addKey("SKU", sku, entry)
+		near := nearSKU(sku)
+		if !nearSeen[near] {
+			nearSeen[near] = true
+			l.addKey("NearSKU", near, entry)
+		}
+	}
+}
+
+// distinctValues counts how many different actual values are in a group
+func (g *libraryGroup) distinctValues() int {
+	values := map[string]bool{}
+	for _, entry := range g.entries {
+		values[entry.Value] = true
+	}
+	return len(values)
+}
+
+// write outputs the duplicates section at the end of the report
+func (l *libraryIndex) write(outfile io.Writer) {
+	fmt.Fprintf(outfile, "\nDuplicates (%v documents checked)\n", l.collected)
+	fmt.Fprintf(outfile, "%v\n", strings.Join([]string{
+		"Kind",
+		"Key",
+		"Value",
+		"Name",
+		"Path",
+		"OnshapeURL"}, "`"))
+	for _, fullKey := range l.order {
+		group := l.groups[fullKey]
+		if len(group.entries) < 2 {
+			continue
+		}
+		kind := strings.SplitN(fullKey, "`", 2)[0]
+		// A near duplicate where everything is spelled the same is already reported as an exact duplicate
+		if (kind == "NearName" || kind == "NearSKU") && group.distinctValues() < 2 {
+			continue
+		}
+		for _, entry := range group.entries {
+			fmt.Fprintf(outfile, "%v`%v`%v`%v`%v\n", group.key, entry.Value, entry.Name, entry.Path, entry.OnshapeURL)
+		}
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"log"
 	"os"
+	"regexp"
 	"runtime"
 
 	"github.com/toebes/go-client/onshape"
@@ -25,6 +27,7 @@ func (i *arrayFlags) Set(value string) error {
 type workItem struct {
 	order      int
 	parentPath string
+	folderID   string
 	element    onshape.BTGlobalTreeMagicNodeInfo
 	finished   bool
 }
@@ -38,15 +41,38 @@ type doneItem struct {
 
 var (
 	// Command-line flags
-	folderIDs    arrayFlags
-	apiAccessKey string
-	apiSecretKey string
-	onshapeDebug bool
-	filepat      string
-	dirpat       string
-	fixvendor    string
-	logfile      string
-	numWorkers   int
+	folderIDs         arrayFlags
+	catalogFiles      arrayFlags
+	apiAccessKey      string
+	apiSecretKey      string
+	onshapeDebug      bool
+	filepat           string
+	dirpat            string
+	fixvendor         string
+	logfile           string
+	summaryfile       string
+	numWorkers        int
+	target            string
+	checkUnversioned  bool
+	versionTemplate   string
+	maxConfigurations int
+	configFile        string
+	checkMassProps    bool
+	fixSKU            bool
+	fixFolderVendor   bool
+	fixURL            bool
+	fixRevision       bool
+	fixName           bool
+	fixBOM            bool
+	listParts         bool
+	dryRun            bool
+
+	// Version name pattern derived from the -target flag (nil matches any version)
+	targetVersionPattern *regexp.Regexp
+	// Policy settings loaded from the -config file
+	auditConfig AuditConfig
+	// Vendor products loaded from the -catalog files (nil when there are none)
+	catalog *Catalog
 )
 
 // MaxParallelism determines the maximum number of threads that it is reasonable to run
@@ -68,10 +94,45 @@ func main() {
 	flag.StringVar(&apiSecretKey, "secret", "", "Onshape API Secret key")
 	flag.StringVar(&apiAccessKey, "access", "", "Onshape API Access key")
 	flag.StringVar(&logfile, "logfile", "outofshape.txt", "Log file to write generated names to")
+	flag.StringVar(&summaryfile, "summary", "outofshape-folders.txt", "File to write the folder summary to")
 	flag.IntVar(&numWorkers, "threads", MaxParallelism()-2, "Maximum number of worker threads")
 	flag.Var(&folderIDs, "fid", "folder id(s) to include in scan")
+	flag.StringVar(&target, "target", targetWorkspace, "What to audit: workspace, latest-version or a pattern matching a version name")
+	flag.BoolVar(&checkUnversioned, "unversioned", false, "Report documents whose workspace has changes that are not in the latest version")
+	flag.StringVar(&versionTemplate, "version", "", "Create a version with this name after fixing a document ({date} and {name} are replaced)")
+	flag.IntVar(&maxConfigurations, "configs", 0, "Maximum number of configurations to audit for each configurable part (0 to skip)")
+	flag.StringVar(&configFile, "config", "", "JSON file with the audit policy settings")
+	flag.BoolVar(&checkMassProps, "massprops", false, "Check the mass properties and size of the main parts")
+	flag.BoolVar(&fixSKU, "fixsku", false, "Correct Part numbers which only differ from the vendor format by whitespace or dashes")
+	flag.BoolVar(&fixFolderVendor, "fixfoldervendor", false, "Set the Vendor of parts and assemblies from the vendor folder they are in")
+	flag.Var(&catalogFiles, "catalog", "Vendor catalog CSV file(s) of SKU, product name and product URL to compare against, given as Vendor=file.csv (the vendor defaults to the file name)")
+	flag.BoolVar(&fixURL, "fixurl", false, "Rewrite vendor product URLs to their canonical https form without tracking parameters")
+	flag.BoolVar(&fixRevision, "fixrevision", false, "Mark the parts in helper PARTS studios as Not revision managed")
+	flag.BoolVar(&fixName, "fixname", false, "Rename Part Studios, Assemblies and main parts whose names only differ from the document name by a name rewrite")
+	flag.BoolVar(&fixBOM, "fixbom", false, "Set Exclude from BOM on helper PARTS studio parts and clear it on main parts")
+	flag.BoolVar(&listParts, "parts", false, "Report a row for every tab and part beneath each document")
+	flag.BoolVar(&dryRun, "dryrun", false, "Report the fixes that would be made without changing anything in Onshape")
 	flag.Parse()
 
+	var err error
+	auditConfig, err = LoadAuditConfig(configFile)
+	if err != nil {
+		log.Fatalf("Unable to load -config: %v", err)
+	}
+	if len(catalogFiles) > 0 {
+		catalog, err = LoadCatalog(catalogFiles)
+		if err != nil {
+			log.Fatalf("Unable to load -catalog: %v", err)
+		}
+	}
+
+	if target != targetWorkspace {
+		targetVersionPattern, err = targetPattern(target)
+		if err != nil {
+			log.Fatalf("Invalid -target version pattern '%v': %v", target, err)
+		}
+	}
+
 	// Queue globals
 	workQueue := make(chan workItem, numWorkers*10)
 	doneQueue := make(chan doneItem, numWorkers*10)
@@ -93,7 +154,7 @@ func main() {
 
 	ctx := context.WithValue(context.Background(), onshape.ContextAPIKeys, onshape.APIKeys{SecretKey: apiSecretKey, AccessKey: apiAccessKey})
 
-	go outputThread(numWorkers, logfile, doneQueue, allDone)
+	go outputThread(numWorkers, logfile, summaryfile, doneQueue, allDone)
 	for i := 0; i < numWorkers; i++ {
 		go fileThread(ctx, client, i, workQueue, doneQueue)
 	}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/toebes/go-client/onshape"
+)
+
+// configurationParameter is the simplified form of a single configuration input for an element.
+// Only list (enum) and checkbox (boolean) inputs are tracked since they are what select the different parts
+type configurationParameter struct {
+	ID           string
+	Name         string
+	DefaultValue string
+	Values       []string // Values the parameter can take
+	Names        []string // Human friendly names for the values
+}
+
+// configurationSample is a single configuration that we want to audit
+type configurationSample struct {
+	Description string // Human readable description such as "Size=Small"
+	Encoded     string // Configuration string to pass to the API such as "List_abc=Small"
+}
+
+// OnshapeGetConfigurationParameters gets the list and checkbox configuration parameters for an element
+func OnshapeGetConfigurationParameters(ctx context.Context, client *onshape.APIClient, did string, wvm string, wvmid string, eid string) ([]configurationParameter, error) {
+	result := []configurationParameter{}
+	var configResponse onshape.BTConfigurationResponse2019
+	var rawResp *http.Response
+	var err error
+	for delay := 0; delay < 50; delay++ {
+		configResponse, rawResp, err = client.ElementsApi.GetConfiguration(ctx, did, wvm, wvmid, eid).Execute()
+		// If we are rate limited, implement a backoff strategy
+		if err == nil || err.Error() != "429 " {
+			break
+		}
+		fmt.Printf(".......Rate Limited.. Sleeping\n")
+		time.Sleep(time.Duration(delay*50) * time.Millisecond)
+	}
+	if err != nil {
+		return result, err
+	} else if rawResp != nil && rawResp.StatusCode >= 300 {
+		return result, fmt.Errorf("err: Response status: %v", rawResp)
+	}
+	params, hasParams := configResponse.GetConfigurationParametersOk()
+	if !hasParams {
+		return result, nil
+	}
+	for _, param := range *params {
+		// Like the metadata, the parameters are polymorphic so we have to figure out which type we got
+		switch paramIface := param.BTMConfigurationParameter819Interface.(type) {
+		case *onshape.BTMConfigurationParameterEnum105:
+			id, hasID := paramIface.GetParameterIdOk()
+			options, hasOptions := paramIface.GetOptionsOk()
+			if !hasID || !hasOptions {
+				continue
+			}
+			entry := configurationParameter{ID: *id, Name: *id}
+			name, hasName := paramIface.GetParameterNameOk()
+			if hasName {
+				entry.Name = *name
+			}
+			defaultValue, hasDefault := paramIface.GetDefaultValueOk()
+			if hasDefault {
+				entry.DefaultValue = *defaultValue
+			}
+			for _, option := range *options {
+				value, hasValue := option.GetOptionOk()
+				if !hasValue {
+					continue
+				}
+				optionName, hasOptionName := option.GetOptionNameOk()
+				if !hasOptionName {
+					optionName = value
+				}
+				entry.Values = append(entry.Values, *value)
+				entry.Names = append(entry.Names, *optionName)
+			}
+			result = append(result, entry)
+		case *onshape.BTMConfigurationParameterBoolean2550:
+			id, hasID := paramIface.GetParameterIdOk()
+			if !hasID {
+				continue
+			}
+			entry := configurationParameter{ID: *id, Name: *id, Values: []string{"false", "true"}, Names: []string{"false", "true"}}
+			name, hasName := paramIface.GetParameterNameOk()
+			if hasName {
+				entry.Name = *name
+			}
+			defaultValue, hasDefault := paramIface.GetDefaultValueOk()
+			entry.DefaultValue = "false"
+			if hasDefault {
+				entry.DefaultValue = strconv.FormatBool(*defaultValue)
+			}
+			result = append(result, entry)
+		default:
+			// Quantity and string inputs don't pick different parts so we leave them at their defaults
+		}
+	}
+	return result, nil
+}
+
+// SampleConfigurations picks which configurations of an element to audit.
+// Rather than trying every combination (which can easily be thousands), we start with the default configuration
+// and then change one parameter at a time so that every option gets seen at least once.
+// No more than maxSamples configurations will be returned.
+func SampleConfigurations(params []configurationParameter, maxSamples int) []configurationSample {
+	result := []configurationSample{}
+	if len(params) == 0 || maxSamples <= 0 {
+		return result
+	}
+	result = append(result, configurationSample{Description: "Default", Encoded: ""})
+	for _, param := range params {
+		for idx, value := range param.Values {
+			if value == param.DefaultValue {
+				continue
+			}
+			if len(result) >= maxSamples {
+				return result
+			}
+			result = append(result, configurationSample{
+				Description: param.Name + "=" + param.Names[idx],
+				Encoded:     param.ID + "=" + value,
+			})
+		}
+	}
+	return result
+}
+
+// OnshapeGetConfiguredMetadata gets the metadata for a single element in a specific configuration
+func OnshapeGetConfiguredMetadata(ctx context.Context, client *onshape.APIClient, did string, wvm string, wvmid string, eid string, configuration string) (onshape.BTMetadataElementInfo, error) {
+	var metadata onshape.BTMetadataElementInfo
+	var rawResp *http.Response
+	var err error
+	for delay := 0; delay < 50; delay++ {
+		request := client.MetadataApi.GetWMVEMetadata(ctx, did, wvm, wvmid, eid).Depth("2")
+		if configuration != "" {
+			request = request.Configuration(configuration)
+		}
+		metadata, rawResp, err = request.Execute()
+		// If we are rate limited, implement a backoff strategy
+		if err == nil || err.Error() != "429 " {
+			break
+		}
+		fmt.Printf(".......Rate Limited.. Sleeping\n")
+		time.Sleep(time.Duration(delay*50) * time.Millisecond)
+	}
+	if err == nil && rawResp != nil && rawResp.StatusCode >= 300 {
+		err = fmt.Errorf("err: Response status: %v", rawResp)
+	}
+	return metadata, err
+}
+
+// encodeConfigurationURL generates the query string to open a document in a given configuration
+func encodeConfigurationURL(baseURL string, configuration string) string {
+	if configuration == "" {
+		return baseURL
+	}
+	return baseURL + "?configuration=" + strings.ReplaceAll(configuration, ";", "%3B")
+}
@@ -9,16 +9,44 @@ import (
 
 // ColorDataProperties maps a color structure into something more managable
 type ColorDataProperties struct {
-	colorRed    int
-	colorGreen  int
-	colorBlue   int
-	opacity     float64
-	isGenerated bool
-	color       string
+	Red         int
+	Green       int
+	Blue        int
+	Opacity     float64 // 0.0 (transparent) to 1.0 (opaque)
+	IsGenerated bool    // The color was picked automatically by Onshape rather than assigned
+}
+
+// Hex gives the color as a #rrggbb string
+func (c ColorDataProperties) Hex() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.Red, c.Green, c.Blue)
+}
+
+// String gives the color along with the opacity and generated flag for the report
+func (c ColorDataProperties) String() string {
+	result := c.Hex()
+	if c.Opacity != 1.0 {
+		result += fmt.Sprintf(" Opacity:%.2f", c.Opacity)
+	}
+	if c.IsGenerated {
+		result += " AutoGenerated"
+	}
+	return result
+}
+
+// mapColorComponent gets a single 0-255 color component out of a generic map
+func mapColorComponent(data map[string]interface{}, key string) (int, error) {
+	val, found := data[key]
+	if !found {
+		return 0, nil
+	}
+	number, isNumber := val.(float64)
+	if !isNumber {
+		return 0, fmt.Errorf("color %v is not a number: %v", key, val)
+	}
+	return int(number), nil
 }
 
 // MapColorData maps a generic interface into the appropriate color data
-// This code really should sanity check the map a bit more..
 //  	{
 //  	 	"isGenerated" : true,
 //		 	"color" : {
@@ -29,39 +57,39 @@ type ColorDataProperties struct {
 //	 		"opacity" : 255
 // 		}
 func MapColorData(data map[string]interface{}) (ColorDataProperties, error) {
-	result := ColorDataProperties{colorRed: 0.0, colorGreen: 0.0, colorBlue: 0.0, opacity: 1.0, isGenerated: true}
+	var err error
+	result := ColorDataProperties{Red: 0, Green: 0, Blue: 0, Opacity: 1.0, IsGenerated: true}
 	isGenerated, found := data["isGenerated"]
 	if found {
-		result.isGenerated = isGenerated.(bool)
+		generated, isBool := isGenerated.(bool)
+		if !isBool {
+			return result, fmt.Errorf("appearance isGenerated is not a boolean: %v", isGenerated)
+		}
+		result.IsGenerated = generated
 	}
 	opacity, found := data["opacity"]
 	if found {
-		result.opacity = opacity.(float64) / 255.0
+		opacityVal, isNumber := opacity.(float64)
+		if !isNumber {
+			return result, fmt.Errorf("appearance opacity is not a number: %v", opacity)
+		}
+		result.Opacity = opacityVal / 255.0
 	}
 	color, found := data["color"]
 	if found {
-		colorMap := color.(map[string]interface{})
-		red, found := colorMap["red"]
-		if found {
-			result.colorRed = int(red.(float64))
+		colorMap, isMap := color.(map[string]interface{})
+		if !isMap {
+			return result, fmt.Errorf("appearance color is not an object: %v", color)
 		}
-		green, found := colorMap["green"]
-		if found {
-			result.colorRed = int(green.(float64))
+		result.Red, err = mapColorComponent(colorMap, "red")
+		if err == nil {
+			result.Green, err = mapColorComponent(colorMap, "green")
 		}
-		blue, found := colorMap["blue"]
-		if found {
-			result.colorRed = int(blue.(float64))
+		if err == nil {
+			result.Blue, err = mapColorComponent(colorMap, "blue")
 		}
 	}
-	result.color = fmt.Sprintf("#%02x%02x%02x", result.colorRed, result.colorGreen, result.colorBlue)
-	if result.opacity != 1.0 {
-		result.color += fmt.Sprintf(" Opacity:%.2f", result.opacity)
-	}
-	if result.isGenerated {
-		result.color += " AutoGenerated"
-	}
-	return result, nil
+	return result, err
 }
 
 // ConsolidatedProperties is an aggregation of the metadata for an object
@@ -73,6 +101,7 @@ type ConsolidatedProperties struct {
 	ExcludeFromBOM     bool
 	NotRevisionManaged bool
 	State              string
+	Revision           string
 	UnitOfMeasure      string
 	DrawnBy            string
 	DateLastChanged    onshape.JSONTime
@@ -80,11 +109,16 @@ type ConsolidatedProperties struct {
 	LastChangedBy      string
 	Extras             string
 	Color              string
+	Appearance         ColorDataProperties
+	HasAppearance      bool
+	Material           Material
+	Custom             map[string]string // Any other STRING, ENUM, BOOL, DATE and USER properties by name
+	CustomByID         map[string]string // The same properties by property ID
 }
 
 // GetConsolidatedProperties navigates a Metadata array and consolidates the important information into a single structure.
 func GetConsolidatedProperties(metadata []onshape.BTMetadataItemsProperties) (ConsolidatedProperties, error) {
-	var result = ConsolidatedProperties{}
+	var result = ConsolidatedProperties{Custom: map[string]string{}, CustomByID: map[string]string{}}
 	var err error = nil
 	var extra = ""
 	// Iterate over all the elements in the document.
@@ -93,6 +127,7 @@ func GetConsolidatedProperties(metadata []onshape.BTMetadataItemsProperties) (Co
 		// of the property in order to access the correct polymorhpic structure of data
 		metadataType := metadataItem.BTMetadataItemsPropertiesInterface.GetValueType()
 		extradata := ""
+		custom, isCustom := "", false
 		switch metadataType {
 		case "BOOL":
 			propIface := metadataItem.BTMetadataItemsPropertiesInterface.(*onshape.BTMetadataCommonBool)
@@ -105,7 +140,8 @@ func GetConsolidatedProperties(metadata []onshape.BTMetadataItemsProperties) (Co
 				case "Not revision managed":
 					result.NotRevisionManaged = *pval
 				default:
-					extradata = "BOOL:" + *name + "=" + strconv.FormatBool(*pval)
+					custom, isCustom = strconv.FormatBool(*pval), true
+					extradata = "BOOL:" + *name + "=" + custom
 
 				}
 			}
@@ -131,6 +167,7 @@ func GetConsolidatedProperties(metadata []onshape.BTMetadataItemsProperties) (Co
 				case "Date drawn":
 					result.DateDrawn = *pval
 				default:
+					custom, isCustom = (*pval).Format("2006-01-02"), true
 					extradata = *name + "=" + (*pval).Format("Mon Jan _2 15:04:05 2006")
 				}
 
@@ -148,6 +185,7 @@ func GetConsolidatedProperties(metadata []onshape.BTMetadataItemsProperties) (Co
 				case "Tessellation quality":
 					// We will skip it.
 				default:
+					custom, isCustom = *pval, true
 					extradata = "ENUM:" + *name + "=" + *pval
 				}
 			}
@@ -158,28 +196,18 @@ func GetConsolidatedProperties(metadata []onshape.BTMetadataItemsProperties) (Co
 			if hasName && hasPval {
 				switch *name {
 				case "Appearance":
-					colordata, err := MapColorData(*pval)
+					result.Appearance, err = MapColorData(*pval)
+					if err != nil {
+						return result, err
+					}
+					result.HasAppearance = true
+					result.Color = result.Appearance.String()
+				case "Material":
+					result.Material, err = MapMaterialData(*pval)
 					if err != nil {
 						return result, err
 					}
-					extradata = fmt.Sprintf("%v=%v", *name, colordata.color)
 				default:
-					///
-					// Material=map[displayName:Hardened Alloy Steel
-					// id:Hardened Alloy Steel
-					// libraryName:Onshape Material Library
-					// libraryReference:map[documentId:2718281828459eacfeeda11f
-					// 					 elementId:6bbab304a1f64e7d640a2d7d
-					// 					 elementMicroversionId:73414798ff906ef5dcd28cf3
-					// 					 versionId:b5704e766ea5b04d206aee1a]
-					// properties:[map[category:Physical
-					// 				description:Density
-					// 				displayName:Density
-					// 				name:DENS
-					// 				type:REAL
-					// 				units:kg/m^3
-					// 				value:7850]]
-					///
 					extradata = fmt.Sprintf("OBJECT:%v=%v", *name, *pval)
 				}
 			}
@@ -197,7 +225,10 @@ func GetConsolidatedProperties(metadata []onshape.BTMetadataItemsProperties) (Co
 					result.SKU = *pval
 				case "Vendor":
 					result.Vendor = *pval
+				case "Revision":
+					result.Revision = *pval
 				default:
+					custom, isCustom = *pval, true
 					extradata = "STRING:" + *name + "=" + *pval
 				}
 			}
@@ -214,6 +245,7 @@ func GetConsolidatedProperties(metadata []onshape.BTMetadataItemsProperties) (Co
 					case "Drawn by":
 						result.DrawnBy = *userName
 					default:
+						custom, isCustom = *userName, true
 						extradata = *name + "=" + *userName
 					}
 				}
@@ -221,6 +253,14 @@ func GetConsolidatedProperties(metadata []onshape.BTMetadataItemsProperties) (Co
 		default:
 			extradata = "UNHANDLED METADATATYPE:" + metadataType
 		}
+		// Keep anything we don't know about so that company specific properties can be checked
+		if isCustom {
+			name, propertyID := metadataPropertyName(metadataItem)
+			result.Custom[name] = custom
+			if propertyID != "" {
+				result.CustomByID[propertyID] = custom
+			}
+		}
 		if extradata != "" {
 			result.Extras += extra + extradata
 			extra = ", "
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/toebes/go-client/onshape"
+)
+
+// PartMassProperties is the subset of the mass properties and bounding box of a part that we check.
+// Everything is in SI units (kg, m^3 and m)
+type PartMassProperties struct {
+	HasMass bool
+	Mass    float64
+	Volume  float64
+	Size    [3]float64 // Extent of the bounding box in X, Y and Z
+}
+
+// Density computes the density of the part in kg/m^3 (zero if it can't be computed)
+func (m PartMassProperties) Density() float64 {
+	if m.Volume <= 0 {
+		return 0
+	}
+	return m.Mass / m.Volume
+}
+
+// LargestDimension gives the longest side of the bounding box
+func (m PartMassProperties) LargestDimension() float64 {
+	largest := 0.0
+	for _, size := range m.Size {
+		if size > largest {
+			largest = size
+		}
+	}
+	return largest
+}
+
+// OnshapeGetPartMassProperties gets the mass, volume and bounding box for a single part
+func OnshapeGetPartMassProperties(ctx context.Context, client *onshape.APIClient, did string, wvm string, wvmid string, eid string, pid string) (PartMassProperties, error) {
+	result := PartMassProperties{}
+	var massProps onshape.BTMassPropertiesBulkInfo
+	var rawResp *http.Response
+	var err error
+	for delay := 0; delay < 50; delay++ {
+		massProps, rawResp, err = client.PartsApi.GetMassProperties(ctx, did, wvm, wvmid, eid, pid).Execute()
+		// If we are rate limited, implement a backoff strategy
+		if err == nil || err.Error() != "429 " {
+			break
+		}
+		fmt.Printf(".......Rate Limited.. Sleeping\n")
+		time.Sleep(time.Duration(delay*50) * time.Millisecond)
+	}
+	if err != nil {
+		return result, err
+	} else if rawResp != nil && rawResp.StatusCode >= 300 {
+		return result, fmt.Errorf("err: Response status: %v", rawResp)
+	}
+	bodies, hasBodies := massProps.GetBodiesOk()
+	if hasBodies {
+		body, found := (*bodies)[pid]
+		if found {
+			hasMass, hasHasMass := body.GetHasMassOk()
+			result.HasMass = hasHasMass && *hasMass
+			// The mass and volume come back as [nominal, minimum, maximum]
+			mass, hasMassVal := body.GetMassOk()
+			if hasMassVal && len(*mass) > 0 {
+				result.Mass = (*mass)[0]
+			}
+			volume, hasVolume := body.GetVolumeOk()
+			if hasVolume && len(*volume) > 0 {
+				result.Volume = (*volume)[0]
+			}
+		}
+	}
+
+	var box onshape.BTBoundingBoxInfo
+	for delay := 0; delay < 50; delay++ {
+		box, rawResp, err = client.PartsApi.GetBoundingBoxes(ctx, did, wvm, wvmid, eid, pid).Execute()
+		// If we are rate limited, implement a backoff strategy
+		if err == nil || err.Error() != "429 " {
+			break
+		}
+		fmt.Printf(".......Rate Limited.. Sleeping\n")
+		time.Sleep(time.Duration(delay*50) * time.Millisecond)
+	}
+	if err != nil {
+		return result, err
+	} else if rawResp != nil && rawResp.StatusCode >= 300 {
+		return result, fmt.Errorf("err: Response status: %v", rawResp)
+	}
+	lowX, hasLowX := box.GetLowXOk()
+	lowY, hasLowY := box.GetLowYOk()
+	lowZ, hasLowZ := box.GetLowZOk()
+	highX, hasHighX := box.GetHighXOk()
+	highY, hasHighY := box.GetHighYOk()
+	highZ, hasHighZ := box.GetHighZOk()
+	if hasLowX && hasLowY && hasLowZ && hasHighX && hasHighY && hasHighZ {
+		result.Size = [3]float64{*highX - *lowX, *highY - *lowY, *highZ - *lowZ}
+	}
+	return result, nil
+}
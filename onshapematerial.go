@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// MaterialLibraryReference identifies the material library document that a material came from
+type MaterialLibraryReference struct {
+	DocumentID            string
+	ElementID             string
+	ElementMicroversionID string
+	VersionID             string
+}
+
+// MaterialProperty is a single physical property of a material such as the density
+type MaterialProperty struct {
+	Name        string // Short name such as DENS
+	DisplayName string
+	Description string
+	Category    string
+	Type        string // Type of the value such as REAL
+	Units       string // Units of the value such as kg/m^3
+	Value       float64
+	RawValue    string // Value as a string for anything that isn't a number
+}
+
+// Material is the typed form of the Material metadata property
+type Material struct {
+	ID               string
+	DisplayName      string
+	LibraryName      string
+	LibraryReference MaterialLibraryReference
+	Properties       []MaterialProperty
+}
+
+// IsEmpty tells us when no material has been assigned
+func (m Material) IsEmpty() bool {
+	return m.DisplayName == "" && m.ID == ""
+}
+
+// String gives the name of the material for the report
+func (m Material) String() string {
+	if m.DisplayName != "" {
+		return m.DisplayName
+	}
+	return m.ID
+}
+
+// GetProperty finds a physical property by its short name (such as DENS)
+func (m Material) GetProperty(name string) (MaterialProperty, bool) {
+	for _, prop := range m.Properties {
+		if prop.Name == name {
+			return prop, true
+		}
+	}
+	return MaterialProperty{}, false
+}
+
+// mapString gets a string out of a generic map, returning blank if it isn't there or isn't a string
+func mapString(data map[string]interface{}, key string) string {
+	val, found := data[key]
+	if !found {
+		return ""
+	}
+	str, isString := val.(string)
+	if !isString {
+		return ""
+	}
+	return str
+}
+
+// MapMaterialData maps a generic interface into the appropriate material data
+//
+//	{
+//		"displayName" : "Hardened Alloy Steel",
+//		"id" : "Hardened Alloy Steel",
+//		"libraryName" : "Onshape Material Library",
+//		"libraryReference" : {
+//			"documentId" : "2718281828459eacfeeda11f",
+//			"elementId" : "6bbab304a1f64e7d640a2d7d",
+//			"elementMicroversionId" : "73414798ff906ef5dcd28cf3",
+//			"versionId" : "b5704e766ea5b04d206aee1a"
+//		},
+//		"properties" : [ {
+//			"category" : "Physical",
+//			"description" : "Density",
+//			"displayName" : "Density",
+//			"name" : "DENS",
+//			"type" : "REAL",
+//			"units" : "kg/m^3",
+//			"value" : 7850
+//		} ]
+//	}
+func MapMaterialData(data map[string]interface{}) (Material, error) {
+	result := Material{
+		ID:          mapString(data, "id"),
+		DisplayName: mapString(data, "displayName"),
+		LibraryName: mapString(data, "libraryName"),
+	}
+	libref, found := data["libraryReference"]
+	if found && libref != nil {
+		librefMap, isMap := libref.(map[string]interface{})
+		if !isMap {
+			return result, fmt.Errorf("material libraryReference is not an object")
+		}
+		result.LibraryReference = MaterialLibraryReference{
+			DocumentID:            mapString(librefMap, "documentId"),
+			ElementID:             mapString(librefMap, "elementId"),
+			ElementMicroversionID: mapString(librefMap, "elementMicroversionId"),
+			VersionID:             mapString(librefMap, "versionId"),
+		}
+	}
+	props, found := data["properties"]
+	if found && props != nil {
+		propList, isList := props.([]interface{})
+		if !isList {
+			return result, fmt.Errorf("material properties is not an array")
+		}
+		for _, prop := range propList {
+			propMap, isMap := prop.(map[string]interface{})
+			if !isMap {
+				continue
+			}
+			matProp := MaterialProperty{
+				Name:        mapString(propMap, "name"),
+				DisplayName: mapString(propMap, "displayName"),
+				Description: mapString(propMap, "description"),
+				Category:    mapString(propMap, "category"),
+				Type:        mapString(propMap, "type"),
+				Units:       mapString(propMap, "units"),
+			}
+			switch value := propMap["value"].(type) {
+			case float64:
+				matProp.Value = value
+				matProp.RawValue = strconv.FormatFloat(value, 'g', -1, 64)
+			case string:
+				matProp.RawValue = value
+				matProp.Value, _ = strconv.ParseFloat(value, 64)
+			}
+			result.Properties = append(result.Properties, matProp)
+		}
+	}
+	return result, nil
+}
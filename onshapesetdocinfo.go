@@ -7,8 +7,11 @@ import (
 	"github.com/toebes/go-client/onshape"
 )
 
-// OnshapeSetDocumentDescription sets the description field on a document
+// OnshapeSetDocumentDescription sets the description field on a document (skipped with -dryrun)
 func OnshapeSetDocumentDescription(ctx context.Context, client *onshape.APIClient, did string, description string) error {
+	if dryRun {
+		return nil
+	}
 	docParams := onshape.NewBTDocumentParams()
 	docParams.SetDescription(description)
 	rawResp, err := client.DocumentsApi.UpdateDocumentAttributes(ctx, did).BTDocumentParams(*docParams).Execute()
@@ -4,6 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/toebes/go-client/onshape"
 )
@@ -15,6 +18,10 @@ import (
 // SetPartMetadata allows updating a metadata property
 func SetPartMetadata(ctx context.Context, client *onshape.APIClient, did string, wv string, wvid string, eid string, pid string, href string, partProps []onshape.BTMetadataItemsProperties, field string, value interface{}) error {
 	body, err := GenMetadataSetBody(partProps, field, value)
+	if dryRun {
+		// Only make sure that the property exists
+		return err
+	}
 	if err == nil {
 		// Needs to be:
 		//   {
@@ -47,6 +54,10 @@ func SetPartMetadata(ctx context.Context, client *onshape.APIClient, did string,
 // SetMetadata allows updating a metadata property
 func SetMetadata(ctx context.Context, client *onshape.APIClient, did string, wv string, wvid string, href string, partProps []onshape.BTMetadataItemsProperties, field string, value interface{}) error {
 	body, err := GenMetadataSetBody(partProps, field, value)
+	if dryRun {
+		// Only make sure that the property exists
+		return err
+	}
 	if err == nil {
 		// the body actually has to be an array
 		items := map[string]interface{}{"href": href, "properties": []interface{}{body}}
@@ -65,7 +76,9 @@ func SetMetadata(ctx context.Context, client *onshape.APIClient, did string, wv
 
 }
 
-// GenMetadataSetBody generates
+// GenMetadataSetBody generates the body to update a single property.
+// The value is checked against the type of the property using the typed setters below
+// so that mistakes are caught before they get to Onshape
 func GenMetadataSetBody(partProps []onshape.BTMetadataItemsProperties, field string, value interface{}) (result interface{}, err error) {
 	// We need to know the propertyID
 
@@ -79,70 +92,250 @@ func GenMetadataSetBody(partProps []onshape.BTMetadataItemsProperties, field str
 	// 	  }]
 	//   }
 
-	result = nil
-	err = fmt.Errorf("Unable to find propertyID for field '%v'", field)
+	switch typed := value.(type) {
+	case bool:
+		return GenMetadataSetBool(partProps, field, typed)
+	case time.Time:
+		return GenMetadataSetDate(partProps, field, typed)
+	case string:
+		// A string can be written to several types of properties, so it depends on what the property is
+		metadataItem, _, err := findMetadataProperty(partProps, field)
+		if err != nil {
+			return nil, err
+		}
+		switch metadataItem.BTMetadataItemsPropertiesInterface.GetValueType() {
+		case "ENUM":
+			return GenMetadataSetEnum(partProps, field, typed)
+		case "USER":
+			return GenMetadataSetUser(partProps, field, typed)
+		case "DATE":
+			date, err := time.Parse("2006-01-02", typed)
+			if err != nil {
+				date, err = time.Parse(time.RFC3339, typed)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("property '%v' is DATE and '%v' is not a date like 2006-01-02", field, typed)
+			}
+			return GenMetadataSetDate(partProps, field, date)
+		}
+		return GenMetadataSetString(partProps, field, typed)
+	case int, int32, int64, uint, uint32, uint64:
+		// Numbers such as a Pack quantity are kept in STRING properties
+		return GenMetadataSetString(partProps, field, fmt.Sprintf("%d", typed))
+	case float32:
+		return GenMetadataSetString(partProps, field, strconv.FormatFloat(float64(typed), 'f', -1, 32))
+	case float64:
+		return GenMetadataSetString(partProps, field, strconv.FormatFloat(typed, 'f', -1, 64))
+	case map[string]interface{}:
+		// An object (such as an Appearance) is passed through as long as the property is an OBJECT
+		return genTypedBody(partProps, field, "OBJECT", typed)
+	}
+	return nil, fmt.Errorf("property '%v' can't be set to a %T value '%v'", field, value, value)
+}
+
+// findMetadataProperty finds a property by name or by ID (for company custom properties)
+func findMetadataProperty(partProps []onshape.BTMetadataItemsProperties, field string) (onshape.BTMetadataItemsProperties, string, error) {
 	// Iterate over all the elements in the document.
 	for _, metadataItem := range partProps {
-		// We need to cast the type to a common type in order to get the name
-		metadataType := metadataItem.BTMetadataItemsPropertiesInterface.GetValueType()
-		var name *string
-		var propertyID *string
-		name = nil
-		propertyID = nil
-		hasName := false
-		hasPropertyID := false
-
-		switch metadataType {
-		case "BOOL":
-			propIface := metadataItem.BTMetadataItemsPropertiesInterface.(*onshape.BTMetadataCommonBool)
-			name, hasName = propIface.GetNameOk()
-			propertyID, hasPropertyID = propIface.GetPropertyIdOk()
-
-		case "CATEGORY":
-			propIface := metadataItem.BTMetadataItemsPropertiesInterface.(*onshape.BTMetadataCommonCategory)
-			name, hasName = propIface.GetNameOk()
-			propertyID, hasPropertyID = propIface.GetPropertyIdOk()
-
-		case "COMPUTED":
-			propIface := metadataItem.BTMetadataItemsPropertiesInterface.(*onshape.BTMetadataCommonComputed)
-			name, hasName = propIface.GetNameOk()
-			propertyID, hasPropertyID = propIface.GetPropertyIdOk()
+		name, propertyID := metadataPropertyName(metadataItem)
+		if propertyID != "" && (name == field || propertyID == field) {
+			return metadataItem, propertyID, nil
+		}
+	}
+	// We didn't find it, so skip out with the default error
+	return onshape.BTMetadataItemsProperties{}, "", fmt.Errorf("Unable to find propertyID for field '%v'", field)
+}
 
-		case "DATE":
-			propIface := metadataItem.BTMetadataItemsPropertiesInterface.(*onshape.BTMetadataCommonDate)
-			name, hasName = propIface.GetNameOk()
-			propertyID, hasPropertyID = propIface.GetPropertyIdOk()
+// checkMetadataType makes sure that a property is the type needed for a value
+func checkMetadataType(metadataItem onshape.BTMetadataItemsProperties, field string, expected string, value interface{}) error {
+	valueType := metadataItem.BTMetadataItemsPropertiesInterface.GetValueType()
+	if valueType != expected {
+		return fmt.Errorf("property '%v' is %v so it can't be set to %v value '%v'", field, valueType, expected, value)
+	}
+	return nil
+}
 
-		case "ENUM":
-			propIface := metadataItem.BTMetadataItemsPropertiesInterface.(*onshape.BTMetadataCommonEnum)
-			name, hasName = propIface.GetNameOk()
-			propertyID, hasPropertyID = propIface.GetPropertyIdOk()
+// genTypedBody builds the body for a property after checking that it is the expected type
+func genTypedBody(partProps []onshape.BTMetadataItemsProperties, field string, expected string, value interface{}) (interface{}, error) {
+	metadataItem, propertyID, err := findMetadataProperty(partProps, field)
+	if err != nil {
+		return nil, err
+	}
+	err = checkMetadataType(metadataItem, field, expected, value)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"propertyId": propertyID, "value": value}, nil
+}
+
+// GenMetadataSetBool generates the body to update a BOOL property such as Exclude from BOM
+func GenMetadataSetBool(partProps []onshape.BTMetadataItemsProperties, field string, value bool) (interface{}, error) {
+	return genTypedBody(partProps, field, "BOOL", value)
+}
 
-		case "OBJECT":
-			propIface := metadataItem.BTMetadataItemsPropertiesInterface.(*onshape.BTMetadataCommonObject)
-			name, hasName = propIface.GetNameOk()
-			propertyID, hasPropertyID = propIface.GetPropertyIdOk()
+// GenMetadataSetString generates the body to update a STRING property such as Part number
+func GenMetadataSetString(partProps []onshape.BTMetadataItemsProperties, field string, value string) (interface{}, error) {
+	return genTypedBody(partProps, field, "STRING", value)
+}
 
-		case "STRING":
-			propIface := metadataItem.BTMetadataItemsPropertiesInterface.(*onshape.BTMetadataCommonString)
-			name, hasName = propIface.GetNameOk()
-			propertyID, hasPropertyID = propIface.GetPropertyIdOk()
+// GenMetadataSetDate generates the body to update a DATE property such as Date drawn
+func GenMetadataSetDate(partProps []onshape.BTMetadataItemsProperties, field string, value time.Time) (interface{}, error) {
+	return genTypedBody(partProps, field, "DATE", value.UTC().Format(time.RFC3339))
+}
 
-		case "USER":
-			propIface := metadataItem.BTMetadataItemsPropertiesInterface.(*onshape.BTMetadataCommonUser)
-			name, hasName = propIface.GetNameOk()
-			propertyID, hasPropertyID = propIface.GetPropertyIdOk()
+// GenMetadataSetUser generates the body to update a USER property such as Drawn by.  The value is the ID of the user
+func GenMetadataSetUser(partProps []onshape.BTMetadataItemsProperties, field string, userID string) (interface{}, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, fmt.Errorf("property '%v' is USER and needs a user ID", field)
+	}
+	return genTypedBody(partProps, field, "USER", userID)
+}
 
-		default:
+// GenMetadataSetEnum generates the body to update an ENUM property such as State or Unit of measure.
+// The value has to be one that the property allows, either by value or by the label shown in Onshape
+func GenMetadataSetEnum(partProps []onshape.BTMetadataItemsProperties, field string, value string) (interface{}, error) {
+	metadataItem, _, err := findMetadataProperty(partProps, field)
+	if err != nil {
+		return nil, err
+	}
+	err = checkMetadataType(metadataItem, field, "ENUM", value)
+	if err != nil {
+		return nil, err
+	}
+	propIface := metadataItem.BTMetadataItemsPropertiesInterface.(*onshape.BTMetadataCommonEnum)
+	enumValues, hasEnumValues := propIface.GetEnumValuesOk()
+	if !hasEnumValues {
+		// We don't know what is allowed, so let Onshape decide
+		return genTypedBody(partProps, field, "ENUM", value)
+	}
+	allowed := []string{}
+	for _, enumValue := range *enumValues {
+		enumVal, hasValue := enumValue.GetValueOk()
+		if !hasValue {
+			continue
+		}
+		allowed = append(allowed, *enumVal)
+		label, hasLabel := enumValue.GetLabelOk()
+		if *enumVal == value || (hasLabel && strings.EqualFold(*label, value)) {
+			return genTypedBody(partProps, field, "ENUM", *enumVal)
 		}
+	}
+	return nil, fmt.Errorf("property '%v' is ENUM and '%v' is not one of the allowed values: %v", field, value, strings.Join(allowed, ", "))
+}
+
+// MetadataUpdate is a single property change which is saved up so that all of the changes
+// to a document can be sent at once with SetMetadataBatch
+type MetadataUpdate struct {
+	Href      string      // Item (element or part) to update
+	Property  interface{} // Body generated by GenMetadataSetBody
+	Fix       string      // Description of the change for the report
+	Check     string      // Finding that the change takes care of (blank if it is reported elsewhere)
+	ElementID string      // Tab that the change is for so that -parts can report the finding on it
+	PartID    string      // Part that the change is for so that -parts can report the finding on it
+}
+
+// QueueMetadata generates the body for a property change and adds the update to the list of updates.
+// The check is only reported once the updates have been sent so that it can say whether it was fixed
+func QueueMetadata(updates []MetadataUpdate, update MetadataUpdate, partProps []onshape.BTMetadataItemsProperties, field string, value interface{}) ([]MetadataUpdate, error) {
+	body, err := GenMetadataSetBody(partProps, field, value)
+	if err != nil {
+		return updates, err
+	}
+	update.Property = body
+	return append(updates, update), nil
+}
 
-		if hasName && hasPropertyID && *name == field {
-			// Ok they have the field that they want to
-			err = nil
-			result = map[string]interface{}{"propertyId": *propertyID, "value": value}
-			return
+// SetMetadataBatch sends all of the queued property changes for a document in a single request.
+// Changes to the same item are combined into one entry
+func SetMetadataBatch(ctx context.Context, client *onshape.APIClient, did string, wv string, wvid string, updates []MetadataUpdate) error {
+	if len(updates) == 0 || dryRun {
+		return nil
+	}
+	hrefs := []string{}
+	properties := map[string][]interface{}{}
+	for _, update := range updates {
+		if _, found := properties[update.Href]; !found {
+			hrefs = append(hrefs, update.Href)
 		}
+		properties[update.Href] = append(properties[update.Href], update.Property)
 	}
-	// We didn't find it, so skip out with the default error
-	return
+	items := []interface{}{}
+	for _, href := range hrefs {
+		items = append(items, map[string]interface{}{"href": href, "properties": properties[href]})
+	}
+	jsonBody, err := json.Marshal(map[string]interface{}{"items": items})
+	if err != nil {
+		return err
+	}
+	_, rawResp, err := client.MetadataApi.UpdateWVMetadata(ctx, did, wv, wvid).Body(string(jsonBody)).Execute()
+	if err == nil && rawResp != nil && rawResp.StatusCode >= 300 {
+		err = fmt.Errorf("err: Response status: %v", rawResp)
+	}
+	return err
+}
+
+// metadataPropertyName gets the name and property ID of a metadata property of any type.
+// Blank strings are returned for anything which is missing
+func metadataPropertyName(metadataItem onshape.BTMetadataItemsProperties) (string, string) {
+	// We need to cast the type to a common type in order to get the name
+	metadataType := metadataItem.BTMetadataItemsPropertiesInterface.GetValueType()
+	var name *string
+	var propertyID *string
+	name = nil
+	propertyID = nil
+	hasName := false
+	hasPropertyID := false
+
+	switch metadataType {
+	case "BOOL":
+		propIface := metadataItem.BTMetadataItemsPropertiesInterface.(*onshape.BTMetadataCommonBool)
+		name, hasName = propIface.GetNameOk()
+		propertyID, hasPropertyID = propIface.GetPropertyIdOk()
+
+	case "CATEGORY":
+		propIface := metadataItem.BTMetadataItemsPropertiesInterface.(*onshape.BTMetadataCommonCategory)
+		name, hasName = propIface.GetNameOk()
+		propertyID, hasPropertyID = propIface.GetPropertyIdOk()
+
+	case "COMPUTED":
+		propIface := metadataItem.BTMetadataItemsPropertiesInterface.(*onshape.BTMetadataCommonComputed)
+		name, hasName = propIface.GetNameOk()
+		propertyID, hasPropertyID = propIface.GetPropertyIdOk()
+
+	case "DATE":
+		propIface := metadataItem.BTMetadataItemsPropertiesInterface.(*onshape.BTMetadataCommonDate)
+		name, hasName = propIface.GetNameOk()
+		propertyID, hasPropertyID = propIface.GetPropertyIdOk()
+
+	case "ENUM":
+		propIface := metadataItem.BTMetadataItemsPropertiesInterface.(*onshape.BTMetadataCommonEnum)
+		name, hasName = propIface.GetNameOk()
+		propertyID, hasPropertyID = propIface.GetPropertyIdOk()
+
+	case "OBJECT":
+		propIface := metadataItem.BTMetadataItemsPropertiesInterface.(*onshape.BTMetadataCommonObject)
+		name, hasName = propIface.GetNameOk()
+		propertyID, hasPropertyID = propIface.GetPropertyIdOk()
+
+	case "STRING":
+		propIface := metadataItem.BTMetadataItemsPropertiesInterface.(*onshape.BTMetadataCommonString)
+		name, hasName = propIface.GetNameOk()
+		propertyID, hasPropertyID = propIface.GetPropertyIdOk()
+
+	case "USER":
+		propIface := metadataItem.BTMetadataItemsPropertiesInterface.(*onshape.BTMetadataCommonUser)
+		name, hasName = propIface.GetNameOk()
+		propertyID, hasPropertyID = propIface.GetPropertyIdOk()
+
+	default:
+	}
+
+	result, resultID := "", ""
+	if hasName {
+		result = *name
+	}
+	if hasPropertyID {
+		resultID = *propertyID
+	}
+	return result, resultID
 }
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/toebes/go-client/onshape"
+)
+
+const (
+	// targetWorkspace audits the default workspace of each document
+	targetWorkspace = "workspace"
+	// targetLatestVersion audits the most recently created version of each document
+	targetLatestVersion = "latest-version"
+)
+
+// OnshapeGetVersions gets the list of versions for a document
+func OnshapeGetVersions(ctx context.Context, client *onshape.APIClient, did string) ([]onshape.BTVersionInfo, error) {
+	var versions []onshape.BTVersionInfo
+	var rawResp *http.Response
+	var err error
+	for delay := 0; delay < 50; delay++ {
+		versions, rawResp, err = client.DocumentsApi.GetDocumentVersions(ctx, did).Execute()
+		// If we are rate limited, implement a backoff strategy
+		if err == nil || err.Error() != "429 " {
+			break
+		}
+		fmt.Printf(".......Rate Limited.. Sleeping\n")
+		time.Sleep(time.Duration(delay*50) * time.Millisecond)
+	}
+	if err == nil && rawResp != nil && rawResp.StatusCode >= 300 {
+		err = fmt.Errorf("err: Response status: %v", rawResp)
+	}
+	return versions, err
+}
+
+// OnshapeGetWorkspaceMicroversion gets the current microversion of a workspace in a document
+func OnshapeGetWorkspaceMicroversion(ctx context.Context, client *onshape.APIClient, did string, wid string) (string, error) {
+	var workspaces []onshape.BTWorkspaceInfo
+	var rawResp *http.Response
+	var err error
+	for delay := 0; delay < 50; delay++ {
+		workspaces, rawResp, err = client.DocumentsApi.GetDocumentWorkspaces(ctx, did).Execute()
+		// If we are rate limited, implement a backoff strategy
+		if err == nil || err.Error() != "429 " {
+			break
+		}
+		fmt.Printf(".......Rate Limited.. Sleeping\n")
+		time.Sleep(time.Duration(delay*50) * time.Millisecond)
+	}
+	if err != nil {
+		return "", err
+	} else if rawResp != nil && rawResp.StatusCode >= 300 {
+		return "", fmt.Errorf("err: Response status: %v", rawResp)
+	}
+	for _, workspace := range workspaces {
+		id, hasID := workspace.GetIdOk()
+		microversion, hasMicroversion := workspace.GetMicroversionOk()
+		if hasID && hasMicroversion && *id == wid {
+			return *microversion, nil
+		}
+	}
+	return "", fmt.Errorf("unable to find workspace %v", wid)
+}
+
+// FindLatestVersion returns the most recently created version whose name matches the pattern.
+// A nil pattern matches any version.  If nothing matches, nil is returned
+func FindLatestVersion(versions []onshape.BTVersionInfo, pattern *regexp.Regexp) *onshape.BTVersionInfo {
+	var latest *onshape.BTVersionInfo
+	var latestTime time.Time
+	for idx := range versions {
+		version := &versions[idx]
+		name, hasName := version.GetNameOk()
+		if pattern != nil && (!hasName || !pattern.MatchString(*name)) {
+			continue
+		}
+		created, hasCreated := version.GetCreatedAtOk()
+		if latest == nil || (hasCreated && created.After(latestTime)) {
+			latest = version
+			if hasCreated {
+				latestTime = created.Time
+			}
+		}
+	}
+	return latest
+}
+
+// targetPattern converts the -target option into the pattern used to select a version.
+// It returns nil when any version will do
+func targetPattern(target string) (*regexp.Regexp, error) {
+	if target == targetLatestVersion {
+		return nil, nil
+	}
+	return regexp.Compile(target)
+}
+
+// workspaceHref converts a metadata href that refers to a version into the matching href in the workspace.
+// Versions are immutable so any changes have to be written back to the workspace
+func workspaceHref(href string, wvm string, wvmid string, wid string) string {
+	if wvm == "w" {
+		return href
+	}
+	return strings.Replace(href, "/"+wvm+"/"+wvmid+"/", "/w/"+wid+"/", 1)
+}
+
+// OnshapeCreateVersion creates a new version of the workspace in a document (skipped with -dryrun)
+func OnshapeCreateVersion(ctx context.Context, client *onshape.APIClient, did string, wid string, name string, description string) error {
+	if dryRun {
+		return nil
+	}
+	versionParams := onshape.NewBTVersionOrWorkspaceParams()
+	versionParams.SetDocumentId(did)
+	versionParams.SetWorkspaceId(wid)
+	versionParams.SetName(name)
+	versionParams.SetDescription(description)
+	_, rawResp, err := client.DocumentsApi.CreateVersion(ctx, did).BTVersionOrWorkspaceParams(*versionParams).Execute()
+	if err == nil && rawResp != nil && rawResp.StatusCode >= 300 {
+		err = fmt.Errorf("err: Response status: %v", rawResp)
+	}
+	return err
+}
+
+// expandVersionName fills in the {date} and {name} placeholders of the version name template
+func expandVersionName(template string, documentName string, now time.Time) string {
+	return strings.NewReplacer("{date}", now.Format("2006-01-02"), "{name}", documentName).Replace(template)
+}
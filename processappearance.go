@@ -0,0 +1,21 @@
+package main
+
+import "strings"
+
+// checkAppearance applies the vendor color policy to a single main part
+func checkAppearance(result *fileInfo, vendor *VendorPolicy, partName string, appearance ColorDataProperties, hasAppearance bool) {
+	if vendor == nil || !hasAppearance {
+		return
+	}
+	if vendor.ForbidGeneratedColors && appearance.IsGenerated {
+		result.AddCheck("Auto-generated color %v on \"%v\"", appearance.Hex(), partName)
+	}
+	if len(vendor.Palette) > 0 {
+		for _, color := range vendor.Palette {
+			if strings.EqualFold(color, appearance.Hex()) {
+				return
+			}
+		}
+		result.AddCheck("Color %v on \"%v\" is not in the %v palette", appearance.Hex(), partName, vendor.Name)
+	}
+}
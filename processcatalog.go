@@ -0,0 +1,64 @@
+package main
+
+import "strings"
+
+// sameProductURL compares two product URLs ignoring case, whitespace and a trailing slash
+func sameProductURL(a string, b string) bool {
+	return strings.EqualFold(strings.TrimRight(strings.TrimSpace(a), "/"), strings.TrimRight(strings.TrimSpace(b), "/"))
+}
+
+// catalogVendor decides which vendor's catalog applies to a document: the Vendor property if there is one
+// and otherwise the vendor whose folder holds the document
+func catalogVendor(result *fileInfo) string {
+	vendor := result.Vendor.result().Value
+	if vendor == "" {
+		if policy := auditConfig.vendorByFolder(result.Path); policy != nil {
+			vendor = policy.Name
+		}
+	}
+	return vendor
+}
+
+// checkCatalog compares the Name, SKU and VendorURL for a document against the catalog for its vendor.
+// Documents for vendors without a catalog aren't checked
+func checkCatalog(result *fileInfo) {
+	if catalog == nil {
+		return
+	}
+	vendor := catalogVendor(result)
+	if !catalog.HasVendor(vendor) {
+		return
+	}
+	if len(result.SKU) == 0 {
+		// Without a SKU the best we can do is see if the catalog knows the product by name
+		item, found := catalog.FindName(vendor, result.DocumentName)
+		if found {
+			result.AddCheck("Missing Part number, catalog has '%v'", item.SKU)
+		}
+		return
+	}
+	for _, sku := range result.SKU.values() {
+		item, found := catalog.FindSKU(vendor, sku)
+		if !found {
+			result.AddCheck("Part number '%v' not in %v catalog", sku, vendor)
+			continue
+		}
+		if item.Name != "" && !strings.EqualFold(item.Name, result.DocumentName) {
+			if _, canFix := canFixName(item.Name, result.DocumentName); !canFix {
+				result.AddCheck("Name does not match catalog '%v' for %v", item.Name, sku)
+			}
+		}
+		if item.URL != "" {
+			matchedURL := false
+			for _, vendorURL := range result.VendorURL.values() {
+				if sameProductURL(vendorURL, item.URL) {
+					matchedURL = true
+					break
+				}
+			}
+			if !matchedURL {
+				result.AddCheck("VendorURL does not match catalog '%v' for %v", item.URL, sku)
+			}
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/toebes/go-client/onshape"
+)
+
+// configurationNameMatches determines if the name of a configured part or assembly goes with the document.
+// The " (Configurable)" marker on the document name is ignored and the name may add the configuration on the end
+func configurationNameMatches(name string, documentName string) bool {
+	base := strings.TrimSpace(strings.Replace(documentName, "(Configurable)", "", 1))
+	if strings.EqualFold(name, base) || strings.HasPrefix(strings.ToLower(name), strings.ToLower(base)) {
+		return true
+	}
+	_, canFix := canFixName(name, base)
+	return canFix
+}
+
+// auditConfigurations checks the Part number, Name and Vendor for a sample of the configurations of the main
+// Part Studio or Assembly of a document.  Each configuration gets a sub-row in the result.
+func auditConfigurations(ctx context.Context, client *onshape.APIClient, result *fileInfo, did string, wvm string, wvmid string, eid string, tabType string) error {
+	params, err := OnshapeGetConfigurationParameters(ctx, client, did, wvm, wvmid, eid)
+	if err != nil {
+		return err
+	}
+	samples := SampleConfigurations(params, maxConfigurations)
+	if len(samples) == 0 {
+		// Not a configured element, so there is nothing more to check
+		return nil
+	}
+	baseURL := fmt.Sprintf("https://cad.onshape.com/documents/%v/%v/%v/e/%v", did, wvm, wvmid, eid)
+	seenSKUs := map[string]string{}
+	defaultVendor := ""
+	withFindings := 0
+	for _, sample := range samples {
+		row := makefileInfo()
+		row.Path = result.Path
+		row.FolderID = result.FolderID
+		row.Configuration = sample.Description
+		row.OnshapeURL = encodeConfigurationURL(baseURL, sample.Encoded)
+
+		metadata, err := OnshapeGetConfiguredMetadata(ctx, client, did, wvm, wvmid, eid, sample.Encoded)
+		if err != nil {
+			return err
+		}
+		configured := []ConsolidatedProperties{}
+		if tabType == "Part Studio" {
+			parts, hasParts := metadata.GetPartsOk()
+			if hasParts {
+				partsItems, hasPartsItems := (*parts).GetItemsOk()
+				if hasPartsItems {
+					for _, part := range *partsItems {
+						parttype, hasPartType := part.GetPartTypeOk()
+						partProps, hasPartProps := part.GetPropertiesOk()
+						if hasPartType && *parttype == "solid" && hasPartProps {
+							partConsolidated, err := GetConsolidatedProperties(*partProps)
+							if err != nil {
+								return err
+							}
+							configured = append(configured, partConsolidated)
+						}
+					}
+				}
+			}
+			if len(configured) == 0 {
+				row.AddCheck("No parts in configuration")
+			}
+		} else {
+			properties, hasProperties := metadata.GetPropertiesOk()
+			if hasProperties {
+				consolidated, err := GetConsolidatedProperties(*properties)
+				if err != nil {
+					return err
+				}
+				configured = append(configured, consolidated)
+			}
+		}
+
+		for _, consolidated := range configured {
+			row.Name.set(consolidated.Name, "ConfigName")
+			if !configurationNameMatches(consolidated.Name, result.DocumentName) {
+				row.AddCheck("Configuration name '%v' does not match document name", consolidated.Name)
+			}
+			row.SKU.set(consolidated.SKU, "ConfigPart#")
+			row.Vendor.set(consolidated.Vendor, "ConfigVendor")
+			if strings.TrimSpace(consolidated.SKU) == "" {
+				row.AddCheck("Missing Part number:\"%v\"", consolidated.Name)
+			} else if other, found := seenSKUs[consolidated.SKU]; found && other != sample.Description {
+				row.AddCheck("Part number '%v' also used by %v", consolidated.SKU, other)
+			} else {
+				seenSKUs[consolidated.SKU] = sample.Description
+			}
+			if strings.TrimSpace(consolidated.Vendor) == "" {
+				row.AddCheck("Missing Vendor:\"%v\"", consolidated.Name)
+			} else if defaultVendor == "" {
+				defaultVendor = consolidated.Vendor
+			} else if consolidated.Vendor != defaultVendor {
+				row.AddCheck("Vendor '%v' differs from '%v'", consolidated.Vendor, defaultVendor)
+			}
+		}
+		if row.CheckCount > 0 {
+			withFindings++
+		}
+		result.SubRows = append(result.SubRows, row)
+	}
+	if withFindings > 0 {
+		result.AddCheck("%v of %v configurations have problems", withFindings, len(samples))
+	}
+	return nil
+}
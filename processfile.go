@@ -6,6 +6,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,18 +15,51 @@ import (
 
 // fileInfo is what is passed from the fileThread to the outputThread for writing
 type fileInfo struct {
-	Path       string
-	OnshapeURL string
-	Name       uniqueString
-	SKU        uniqueString
-	Vendor     uniqueString
-	VendorURL  uniqueString
-	Checks     string
+	Path            string
+	DocumentName    string // Name of the document (blank for folders)
+	DescriptionName string // Product name from the document description
+	Tags            string // Tags such as [OBSOLETE] from the document description
+	Notes           string // Free form notes from the document description
+	Lifecycle       string // Active, Obsolete or Discontinued
+	Configuration   string // For a sub-row, the configuration that was audited
+	ElementID       string // For a -parts sub-row, the tab that the row is for
+	PartID          string // For a -parts sub-row of a part, the part that the row is for
+	ItemType        string // For a -parts sub-row, the type of tab or part
+	ExcludeFromBOM  string // For a -parts sub-row, the Exclude from BOM setting
+	OnshapeURL      string
+	Name            uniqueString
+	SKU             uniqueString
+	Vendor          uniqueString
+	VendorURL       uniqueString
+	DrawnBy         uniqueString
+	DateDrawn       uniqueString
+	Revision        uniqueString
+	Material        uniqueString
+	Appearance      uniqueString
+	Mass            uniqueString
+	Volume          uniqueString
+	Size            uniqueString
+	State           uniqueString
+	Tabs            string // Inventory of the tabs in a document
+	Checks          string
+	CheckCount      int
+	Fixes           []string // Changes that were made to the document
+	IsFolder        bool
+	FolderID        string // For a folder, the ID of the folder.  For a document, the ID of the folder containing it
+	ParentID        string // For a folder, the ID of the containing folder
+	Owner           string
+	Created         string
+	Modified        string
+	Documents       int                     // Number of documents directly in a folder
+	Subfolders      int                     // Number of folders directly in a folder
+	SubRows         []fileInfo              // Additional rows to report beneath the document such as one per configuration
+	Properties      map[string]uniqueString // Company properties from the configuration by report column
 }
 
 // AddCheck appands to the checks string
 func (f *fileInfo) AddCheck(format string, parms ...interface{}) {
 	msg := fmt.Sprintf(format, parms...)
+	f.CheckCount++
 	if f.Checks == "" {
 		f.Checks = msg
 	} else {
@@ -33,14 +67,192 @@ func (f *fileInfo) AddCheck(format string, parms ...interface{}) {
 	}
 }
 
+// AddFix records a change which was made to the document
+// With -dryrun nothing is actually changed so the fix is marked as one that would have been made
+func (f *fileInfo) AddFix(format string, parms ...interface{}) {
+	if dryRun {
+		format = "Would fix: " + format
+	}
+	f.Fixes = append(f.Fixes, fmt.Sprintf(format, parms...))
+}
+
+// checkVariants raises a finding for every column where the document doesn't agree with itself.
+// State is left out because helper parts are expected to be in a different state than the main part
+func (f *fileInfo) checkVariants() {
+	columns := []struct {
+		name  string
+		value uniqueString
+	}{
+		{"Name", f.Name}, {"SKU", f.SKU}, {"Vendor", f.Vendor}, {"VendorURL", f.VendorURL},
+		{"Material", f.Material}, {"Appearance", f.Appearance},
+		{"Mass", f.Mass}, {"Volume", f.Volume}, {"Size", f.Size},
+		{"DrawnBy", f.DrawnBy}, {"DateDrawn", f.DateDrawn}, {"Revision", f.Revision},
+	}
+	for _, field := range auditConfig.Properties {
+		columns = append(columns, struct {
+			name  string
+			value uniqueString
+		}{field.column(), f.Properties[field.column()]})
+	}
+	for _, column := range columns {
+		result := column.value.result()
+		if len(result.Variants) == 0 {
+			continue
+		}
+		others := make([]string, 0, len(result.Variants))
+		for _, variant := range result.Variants {
+			if variant.CaseOnly {
+				others = append(others, "'"+variant.Value+"' (case only)")
+			} else {
+				others = append(others, "'"+variant.Value+"'")
+			}
+		}
+		f.AddCheck("%v differs: '%v' vs %v", column.name, result.Value, strings.Join(others, ", "))
+	}
+}
+
+// SetTreeInfo fills in the owner and dates from the global tree information for a document or folder
+func (f *fileInfo) SetTreeInfo(node onshape.BTGlobalTreeMagicNodeInfo) {
+	owner, hasOwner := node.GetOwnerOk()
+	if hasOwner {
+		name, hasName := owner.GetNameOk()
+		if hasName {
+			f.Owner = *name
+		}
+	}
+	created, hasCreated := node.GetCreatedAtOk()
+	if hasCreated {
+		f.Created = formatTreeTime(*created)
+	}
+	modified, hasModified := node.GetModifiedAtOk()
+	if hasModified {
+		f.Modified = formatTreeTime(*modified)
+	}
+}
+
+// formatTreeTime generates a consistent date string for the report
+func formatTreeTime(t onshape.JSONTime) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04")
+}
+
+// standardColumns is the list of built in column names written at the top of the report
+func standardColumns() []string {
+	return []string{
+		"Order",
+		"Path",
+		"Configuration",
+		"ElementID",
+		"PartID",
+		"ItemType",
+		"Name",
+		"SKU",
+		"Vendor",
+		"ExcludeFromBOM",
+		"VendorURL",
+		"DescriptionName",
+		"Tags",
+		"DescriptionNotes",
+		"Lifecycle",
+		"State",
+		"OnshapeURL",
+		"Material",
+		"Appearance",
+		"Mass",
+		"Volume",
+		"Size",
+		"Tabs",
+		"DrawnBy",
+		"DateDrawn",
+		"Revision",
+		"Owner",
+		"Created",
+		"Modified",
+		"Documents",
+		"Subfolders",
+		"Findings",
+		"Notes",
+		"Fixes"}
+}
+
+// reportHeader is the list of column names written at the top of the report
+func reportHeader() []string {
+	result := standardColumns()
+	// Company properties go at the end so that the standard columns are always in the same place
+	for _, field := range auditConfig.Properties {
+		result = append(result, field.column())
+	}
+	return result
+}
+
+// reportColumns generates the values for a single row of the report (less the Order column)
+func (f *fileInfo) reportColumns() []string {
+	documents := ""
+	subfolders := ""
+	findings := strconv.Itoa(f.CheckCount)
+	if f.IsFolder {
+		// The totals for a folder are only known once everything under it has been
+		// written, so they go in the folder summary instead
+		documents = strconv.Itoa(f.Documents)
+		subfolders = strconv.Itoa(f.Subfolders)
+		findings = ""
+	}
+	result := []string{
+		f.Path,
+		f.Configuration,
+		f.ElementID,
+		f.PartID,
+		f.ItemType,
+		f.Name.get(),
+		f.SKU.get(),
+		f.Vendor.get(),
+		f.ExcludeFromBOM,
+		f.VendorURL.get(),
+		f.DescriptionName,
+		f.Tags,
+		f.Notes,
+		f.Lifecycle,
+		f.State.get(),
+		f.OnshapeURL,
+		f.Material.get(),
+		f.Appearance.get(),
+		f.Mass.get(),
+		f.Volume.get(),
+		f.Size.get(),
+		f.Tabs,
+		f.DrawnBy.get(),
+		f.DateDrawn.get(),
+		f.Revision.get(),
+		f.Owner,
+		f.Created,
+		f.Modified,
+		documents,
+		subfolders,
+		findings,
+		f.Checks,
+		strings.Join(f.Fixes, ", ")}
+	for _, field := range auditConfig.Properties {
+		result = append(result, f.Properties[field.column()].get())
+	}
+	return result
+}
+
 // makefileInfo Creates an empty fileInfo structure
 func makefileInfo() fileInfo {
-	fi := fileInfo{Name: uniqueString{}, SKU: uniqueString{}, Vendor: uniqueString{}, VendorURL: uniqueString{}}
+	fi := fileInfo{Name: uniqueString{}, SKU: uniqueString{}, Vendor: uniqueString{}, VendorURL: uniqueString{},
+		DrawnBy: uniqueString{}, DateDrawn: uniqueString{}, Revision: uniqueString{}, Material: uniqueString{},
+		Appearance: uniqueString{}, Mass: uniqueString{}, Volume: uniqueString{}, Size: uniqueString{},
+		State: uniqueString{}, Properties: map[string]uniqueString{}}
+	for _, field := range auditConfig.Properties {
+		fi.Properties[field.column()] = uniqueString{}
+	}
 	return fi
 }
 
 // OutputThread is responsible for printing out all the information found
-func outputThread(numWorkers int, logfile string, doneQueue chan doneItem, allDone chan bool) {
+func outputThread(numWorkers int, logfile string, summaryfile string, doneQueue chan doneItem, allDone chan bool) {
 	running := numWorkers
 	baseEntry := 1
 	linenum := 0
@@ -52,15 +264,10 @@ func outputThread(numWorkers int, logfile string, doneQueue chan doneItem, allDo
 		log.Fatal(err)
 		return
 	}
-	fmt.Fprintf(outfile, "%v`%v`%v`%v`%v`%v`%v`%v\n",
-		"Order",
-		"Path",
-		"Name",
-		"SKU",
-		"Vendor",
-		"VendorURL",
-		"OnshapeURL",
-		"Notes")
+	fmt.Fprintf(outfile, "%v\n", strings.Join(reportHeader(), "`"))
+	folders := folderSummary{}
+	library := libraryIndex{}
+	catalogSeen := map[string]bool{}
 
 	orderQueue := make([]*doneItem, 0, 25)
 	for {
@@ -96,23 +303,22 @@ func outputThread(numWorkers int, logfile string, doneQueue chan doneItem, allDo
 					lastbase = ent.result.Path
 				}
 				// We have the data, so dump it out
-				fmt.Printf("++Output %v(%v): %v`%v`%v`%v`%v`%v`%v\n", ent.order, ent.workerID,
-					ent.result.Path,
-					ent.result.Name.get(),
-					ent.result.SKU.get(),
-					ent.result.Vendor.get(),
-					ent.result.VendorURL.get(),
-					ent.result.OnshapeURL,
-					ent.result.Checks)
+				columns := strings.Join(ent.result.reportColumns(), "`")
+				fmt.Printf("++Output %v(%v): %v\n", ent.order, ent.workerID, columns)
 				linenum++
-				fmt.Fprintf(outfile, "%v`%v`%v`%v`%v`%v`%v`%v\n", linenum,
-					ent.result.Path,
-					ent.result.Name.get(),
-					ent.result.SKU.get(),
-					ent.result.Vendor.get(),
-					ent.result.VendorURL.get(),
-					ent.result.OnshapeURL,
-					ent.result.Checks)
+				fmt.Fprintf(outfile, "%v`%v\n", linenum, columns)
+				folders.add(ent.result)
+				library.add(ent.result)
+				vendor := catalogVendor(&ent.result)
+				for _, sku := range ent.result.SKU.values() {
+					catalogSeen[catalogKey(vendor, nearSKU(sku))] = true
+				}
+				for _, subRow := range ent.result.SubRows {
+					subColumns := strings.Join(subRow.reportColumns(), "`")
+					fmt.Printf("++Output %v(%v): %v\n", ent.order, ent.workerID, subColumns)
+					linenum++
+					fmt.Fprintf(outfile, "%v`%v\n", linenum, subColumns)
+				}
 
 				toprint++
 			}
@@ -123,30 +329,52 @@ func outputThread(numWorkers int, logfile string, doneQueue chan doneItem, allDo
 			//		fmt.Printf("--%v(%v): %v\n", output.order, output.workerID, output.result)
 		}
 	}
+	if summaryfile != "" {
+		summaryout, err := os.Create(summaryfile)
+		if err != nil {
+			log.Print(err)
+		} else {
+			folders.write(summaryout)
+			summaryout.Close()
+		}
+	}
+	library.write(outfile)
+	if catalog != nil {
+		catalog.writeUnmodeled(outfile, catalogSeen)
+	}
 
 	allDone <- true
 }
 
-func canFixName(testname string, basename string) bool {
-	// Ok the description doesn't match, see if we can fix it automatically
-	// "- 2 Pack"
-	// "- 25 Pack "
-	// "- 4 Pack"
-	// "- 4 Pack "
-	// "2 Pack"
-	fixes := []string{"- 2 Pack", "- 25 Pack ", "- 4 Pack", "- 4 Pack ", "2 Pack", "2 Pack ", "- 2 Pack "}
-	for _, fix := range fixes {
-		try := strings.Replace(testname, fix, "", -1)
-		if strings.EqualFold(try, basename) {
-			return true
+// canFixName determines if a name can be automatically fixed to match the base name by applying the
+// configured name rewrites one after another.  When it can, the reasons for the rewrites are returned
+func canFixName(testname string, basename string) (string, bool) {
+	reasons := []string{}
+	seen := map[string]bool{}
+	try := testname
+	for _, rule := range auditConfig.NameRewrites {
+		if rule.patternRegexp == nil {
+			continue
+		}
+		rewritten := rule.patternRegexp.ReplaceAllString(try, rule.Replace)
+		if rewritten == try {
+			continue
+		}
+		try = rewritten
+		if !seen[rule.Reason] {
+			seen[rule.Reason] = true
+			reasons = append(reasons, rule.Reason)
+		}
+		if strings.EqualFold(strings.TrimSpace(try), strings.TrimSpace(basename)) {
+			return strings.Join(reasons, ", "), true
 		}
 	}
-	return false
+	return "", false
 }
 
 // queueFile puts a work item on the queue to be processed by one of the fileThreads
-func queueFile(workQueue chan workItem, order int, parentPath string, element onshape.BTGlobalTreeMagicNodeInfo) error {
-	workQueue <- workItem{order: order, parentPath: parentPath, element: element, finished: false}
+func queueFile(workQueue chan workItem, order int, parentPath string, folderID string, element onshape.BTGlobalTreeMagicNodeInfo) error {
+	workQueue <- workItem{order: order, parentPath: parentPath, folderID: folderID, element: element, finished: false}
 	return nil
 }
 
@@ -170,6 +398,7 @@ func fileThread(ctx context.Context, client *onshape.APIClient, workerID int, wo
 		if err != nil {
 			fmt.Printf("===ERROR (%v):%v/%v\n", err, result.Name, result.SKU)
 		}
+		result.FolderID = request.folderID
 		output := doneItem{order: request.order, workerID: workerID, err: err, result: result, finished: false}
 		doneQueue <- output
 	}
@@ -177,7 +406,7 @@ func fileThread(ctx context.Context, client *onshape.APIClient, workerID int, wo
 
 // processFile Handles an Onshape document
 //
-func processFile(ctx context.Context, client *onshape.APIClient, parentPath string, element onshape.BTGlobalTreeMagicNodeInfo) (fileInfo, error) {
+func processFile(ctx context.Context, client *onshape.APIClient, parentPath string, element onshape.BTGlobalTreeMagicNodeInfo) (reported fileInfo, err error) {
 	var elementTypeName = map[int]string{
 		0: "Part Studio",
 		1: "Assembly",
@@ -190,6 +419,7 @@ func processFile(ctx context.Context, client *onshape.APIClient, parentPath stri
 	}
 	result := makefileInfo()
 	result.Path = parentPath
+	result.SetTreeInfo(element)
 
 	// Get the Document ID and default workspace because the APIs need them to access it.
 	// They are used both for generating the URL to access the document and the API for getting the Metadata
@@ -205,8 +435,19 @@ func processFile(ctx context.Context, client *onshape.APIClient, parentPath stri
 	if !found {
 		return result, fmt.Errorf("unable to get default workspace id")
 	}
+	// Once anything has been fixed there needs to be a version, even when the audit stops early
+	defer func() {
+		versionErr := createFixVersion(ctx, client, &reported, *did, *wvid)
+		if err == nil {
+			err = versionErr
+		}
+	}()
 
 	foundPiece := false
+	mainStates := []string{}
+	bomUpdates := []MetadataUpdate{} // Exclude from BOM changes which are all sent together at the end
+	drawings := 0
+	tabs := makeTabInventory()
 
 	// Pull out the name of the document
 	documentName, hasName := element.GetNameOk()
@@ -214,47 +455,100 @@ func processFile(ctx context.Context, client *onshape.APIClient, parentPath stri
 		*documentName = "<UNNAMED>"
 	}
 	result.Name.set(*documentName, "MainDocument")
+	result.DocumentName = *documentName
 
+	parsed := ParsedDescription{NameLine: -1, URLLine: -1}
+	result.Lifecycle = lifecycleActive
 	description, hasit := element.GetDescriptionOk()
 	if hasit && *description != "" {
 		// We need to parse out the description to confirm that it matches the name and
 		// also find the URL for the product
 		// In the most likely scenario, the document name SHOULD be the first part of the description followed by a carriage return and then the product URL
-		pieces := strings.Split(*description, "\n")
-		if len(pieces) != 2 {
-			if !strings.Contains(*description, "[OBSOLETE]") && !strings.Contains(*description, "[DISCONTINUED]") {
-				result.AddCheck(" Description doesn't have a single carriage return '%v'", strings.ReplaceAll(*description, "\n", "\\n"))
+		// but there may also be tags such as [OBSOLETE] and notes
+		parsed = ParseDescription(*description, auditConfig.Descriptions.Layouts)
+		result.DescriptionName = parsed.Name
+		result.Tags = strings.Join(parsed.Tags, " ")
+		result.Notes = parsed.Notes
+		result.Lifecycle = descriptionLifecycle(parsed, *description)
+		if parsed.Layout == "" {
+			if result.Lifecycle == lifecycleActive {
+				result.AddCheck(" Description doesn't match any layout '%v'", strings.ReplaceAll(*description, "\n", "\\n"))
 			}
 		} else {
-			if strings.EqualFold(pieces[0], *documentName) {
-				result.VendorURL.set(pieces[1], "Main_Description")
-			} else {
-				if canFixName(pieces[0], *documentName) {
-					err := OnshapeSetDocumentDescription(ctx, client, *did, *documentName+"\n"+pieces[1])
+			if parsed.URL != "" {
+				// The URL is checked once we know the Part numbers
+				result.VendorURL.set(parsed.URL, "Main_Description")
+			} else if auditConfig.Descriptions.RequireURL && result.Lifecycle == lifecycleActive {
+				result.AddCheck(" Description has no URL")
+			}
+			if parsed.Name != "" && !strings.EqualFold(parsed.Name, *documentName) {
+				if reason, canFix := canFixName(parsed.Name, *documentName); canFix {
+					oldName := parsed.Name
+					err := OnshapeSetDocumentDescription(ctx, client, *did, parsed.SetLine(parsed.NameLine, *documentName))
 					if err != nil {
 						return result, err
 					}
-				} else {
-					if !strings.Contains(*documentName, "(Configurable)") {
-						result.AddCheck(" Description '%v' does not match main name", pieces[0])
-					}
+					result.AddFix("Description '%v' renamed to '%v' (%v)", oldName, *documentName, reason)
+				} else if maxConfigurations > 0 || !strings.Contains(*documentName, "(Configurable)") {
+					// Configurable documents are only held to the check when -configs audits each configuration
+					result.AddCheck(" Description '%v' does not match main name", parsed.Name)
+				}
+			}
+		}
+	}
+
+	// Figure out whether we are auditing the workspace or one of the versions.
+	// Any changes that we make always go to the workspace since versions can't be changed.
+	wvm := "w"
+	wvmid := *wvid
+	if target != targetWorkspace || checkUnversioned {
+		versions, err := OnshapeGetVersions(ctx, client, *did)
+		if err != nil {
+			return result, err
+		}
+		if checkUnversioned {
+			latest := FindLatestVersion(versions, nil)
+			if latest == nil {
+				result.AddCheck("Document has no versions")
+			} else {
+				microversion, err := OnshapeGetWorkspaceMicroversion(ctx, client, *did, *wvid)
+				if err != nil {
+					return result, err
+				}
+				versionMicroversion, hasMicroversion := latest.GetMicroversionOk()
+				versionName, _ := latest.GetNameOk()
+				if hasMicroversion && *versionMicroversion != microversion && versionName != nil {
+					result.AddCheck("Workspace has changes since version '%v'", *versionName)
 				}
 			}
 		}
+		if target != targetWorkspace {
+			version := FindLatestVersion(versions, targetVersionPattern)
+			if version == nil {
+				result.OnshapeURL = fmt.Sprintf("https://cad.onshape.com/documents/%v/w/%v", *did, *wvid)
+				result.AddCheck("No version matching target '%v'", target)
+				return result, nil
+			}
+			vid, hasVid := version.GetIdOk()
+			if !hasVid {
+				return result, fmt.Errorf("unable to get version id")
+			}
+			wvm = "v"
+			wvmid = *vid
+		}
 	}
 
 	// Construct the URL to access the document
-	result.OnshapeURL = fmt.Sprintf("https://cad.onshape.com/documents/%v/w/%v", *did, *wvid)
+	result.OnshapeURL = fmt.Sprintf("https://cad.onshape.com/documents/%v/%v/%v", *did, wvm, wvmid)
 	result.Path = parentPath
 
 	// Get the Metadata for the document.  This returns the list of lower level tabs in the document
-	// fmt.Printf("Calling: /api/metadata/d/%v/w/%v/e\n", *did, *wvid)
+	// fmt.Printf("Calling: /api/metadata/d/%v/%v/%v/e\n", *did, wvm, wvmid)
 
 	var MetadataNodes onshape.BTMetadataInfo
 	var rawResp *http.Response
-	var err error
 	for delay := 0; delay < 50; delay++ {
-		MetadataNodes, rawResp, err = client.MetadataApi.GetWMVEsMetadata(ctx, *did, "w", *wvid).Depth("5").Execute()
+		MetadataNodes, rawResp, err = client.MetadataApi.GetWMVEsMetadata(ctx, *did, wvm, wvmid).Depth("5").Execute()
 		// If we are rate limited, implement a backoff strategy
 		if err == nil || err.Error() != "429 " {
 			break
@@ -263,7 +557,7 @@ func processFile(ctx context.Context, client *onshape.APIClient, parentPath stri
 		time.Sleep(time.Duration(delay*50) * time.Millisecond)
 	}
 	if err != nil {
-		fmt.Printf("GetWMVEsMetadata error: %v getting %v/w/%v\n", err.Error(), *did, *wvid)
+		fmt.Printf("GetWMVEsMetadata error: %v getting %v/%v/%v\n", err.Error(), *did, wvm, wvmid)
 		return result, err
 	} else if rawResp != nil && rawResp.StatusCode >= 300 {
 		err = fmt.Errorf("err: Response status: %v", rawResp)
@@ -298,6 +592,20 @@ func processFile(ctx context.Context, client *onshape.APIClient, parentPath stri
 		if err != nil {
 			return result, err
 		}
+		tabs.add(tabType, consolidated.Name)
+		// Remember where this tab starts so that -parts can give it the findings from checking it
+		checksBefore, countBefore, subRowsBefore := len(result.Checks), result.CheckCount, len(result.SubRows)
+		partSpans := []partSpan{} // The findings for each part so that -parts can put them on the part instead of the tab
+		elementID := ""
+		if eid, hasEid := subelement.GetElementIdOk(); hasEid {
+			elementID = *eid
+		}
+		if tabType == "Part Studio" || tabType == "Assembly" {
+			consolidated.Name, err = checkElementName(ctx, client, &result, *did, wvm, wvmid, *wvid, tabType, subelement, *properties, consolidated.Name, *documentName)
+			if err != nil {
+				return result, err
+			}
+		}
 		switch tabType {
 		case "Part Studio":
 			eid, hasEid := subelement.GetElementIdOk()
@@ -305,11 +613,6 @@ func processFile(ctx context.Context, client *onshape.APIClient, parentPath stri
 				result.AddCheck("Element ID is missing")
 			}
 			parts, hasParts := subelement.GetPartsOk()
-			if !strings.EqualFold(*documentName, consolidated.Name) &&
-				canFixName(consolidated.Name, *documentName) {
-				// We have to rename the part studio to be the proper name
-				consolidated.Name = *documentName
-			}
 			// For a part studio, either the name is something like "parts" or it is the same name as the document
 			// If it is the same name as the document, there should be a single part and it should contain the information
 			//    about the vendor, not be excluded from BOM
@@ -321,6 +624,13 @@ func processFile(ctx context.Context, client *onshape.APIClient, parentPath stri
 					result.AddCheck("Extra Main Part Studio")
 				}
 				foundPiece = true
+				// Check the Part number and Vendor in each of the configurations if asked to
+				if maxConfigurations > 0 && hasEid {
+					err := auditConfigurations(ctx, client, &result, *did, wvm, wvmid, *eid, tabType)
+					if err != nil {
+						return result, err
+					}
+				}
 				// Check to make sure that there is only a single part
 				if !hasParts {
 					result.AddCheck("Part Studio is Empty")
@@ -344,22 +654,96 @@ func processFile(ctx context.Context, client *onshape.APIClient, parentPath stri
 								if err != nil {
 									return result, err
 								}
+								span := result.startPartSpan(part)
+								// Only a single main part can safely take on the name of the document
+								if fixName && len(*partsItems) == 1 && hasEid && hasPid && hasHref && partConsolidated.Name != *documentName {
+									if reason, canFix := canFixName(partConsolidated.Name, *documentName); canFix {
+										err := SetPartMetadata(ctx, client, *did, "w", *wvid, *eid, *pid, workspaceHref(*href, wvm, wvmid, *wvid), *partProps, "Name", *documentName)
+										if err != nil {
+											return result, err
+										}
+										result.AddFix("Part '%v' renamed to '%v' (%v)", partConsolidated.Name, *documentName, reason)
+										partConsolidated.Name = *documentName
+									}
+								}
+								// The name is recorded after any rename so that a fixed part doesn't show up as a variant
 								result.Name.set(partConsolidated.Name, "PartName")
 								result.VendorURL.set(partConsolidated.Description, "PartDescription")
 								result.SKU.set(partConsolidated.SKU, "PartSku")
 								result.Vendor.set(partConsolidated.Vendor, "PartSku")
+								mainStates = append(mainStates, partConsolidated.State)
+								result.State.set(partConsolidated.State, partConsolidated.Name)
+								checkMainReleased(&result, partConsolidated.Name, partConsolidated.State)
+								checkPackQuantity(&result, *documentName, partConsolidated)
+								checkCustomProperties(&result, partConsolidated.Name, partConsolidated, "Part")
+								result.Material.set(partConsolidated.Material.String(), "PartMaterial")
+								result.Appearance.set(partConsolidated.Color, "PartAppearance")
+								vendorPolicy := auditConfig.vendorPolicy(parentPath, partConsolidated.Vendor)
+								checkMaterial(&result, vendorPolicy, partConsolidated.Name, partConsolidated.Material)
+								checkAppearance(&result, vendorPolicy, partConsolidated.Name, partConsolidated.Appearance, partConsolidated.HasAppearance)
+								sku, canFixSKU := checkSKU(&result, vendorPolicy, partConsolidated.Name, partConsolidated.SKU)
+								if canFixSKU && fixSKU && hasEid && hasPid && hasHref {
+									err := SetPartMetadata(ctx, client, *did, "w", *wvid, *eid, *pid, workspaceHref(*href, wvm, wvmid, *wvid), *partProps, "Part number", sku)
+									if err != nil {
+										return result, err
+									}
+									result.AddFix("Part Part number '%v' set to '%v'", partConsolidated.SKU, sku)
+								}
+								if looksLikeURL(partConsolidated.Description) {
+									vendorURL, canFixURL := checkVendorURL(&result, parentPath, partConsolidated.Vendor, []string{sku}, "Part Description", partConsolidated.Description)
+									if canFixURL && fixURL && hasEid && hasPid && hasHref {
+										err := SetPartMetadata(ctx, client, *did, "w", *wvid, *eid, *pid, workspaceHref(*href, wvm, wvmid, *wvid), *partProps, "Description", vendorURL)
+										if err != nil {
+											return result, err
+										}
+										result.AddFix("Part Description URL set to '%v'", vendorURL)
+									}
+								}
+								if checkMassProps && hasEid && hasPid {
+									massProps, err := OnshapeGetPartMassProperties(ctx, client, *did, wvm, wvmid, *eid, *pid)
+									if err != nil {
+										return result, err
+									}
+									reportMassProperties(&result, massProps)
+									checkMassProperties(&result, partConsolidated.Name, massProps, partConsolidated.Material)
+								}
 
 								// See if we need to fix the Vendor in this case
 								if strings.EqualFold(partConsolidated.Vendor, fixvendor) && partConsolidated.Vendor != fixvendor && hasHref {
-									err := SetPartMetadata(ctx, client, *did, "w", *wvid, *eid, *pid, *href, *partProps, "Vendor", fixvendor)
+									err := SetPartMetadata(ctx, client, *did, "w", *wvid, *eid, *pid, workspaceHref(*href, wvm, wvmid, *wvid), *partProps, "Vendor", fixvendor)
 									if err != nil {
 										return result, err
 									}
+									result.AddFix("Part Vendor '%v' set to '%v'", partConsolidated.Vendor, fixvendor)
+									partConsolidated.Vendor = fixvendor
+								}
+								// Make sure that the Vendor agrees with the folder that the document is in
+								folderVendor, canFixVendor := checkFolderVendor(&result, parentPath, partConsolidated.Name, partConsolidated.Vendor)
+								if canFixVendor && fixFolderVendor && hasEid && hasPid && hasHref {
+									err := SetPartMetadata(ctx, client, *did, "w", *wvid, *eid, *pid, workspaceHref(*href, wvm, wvmid, *wvid), *partProps, "Vendor", folderVendor)
+									if err != nil {
+										return result, err
+									}
+									result.AddFix("Part Vendor '%v' set to '%v'", partConsolidated.Vendor, folderVendor)
 								}
 
 								if partConsolidated.ExcludeFromBOM {
-									result.AddCheck("Main part is excluded from BOM")
+									if fixBOM && hasHref {
+										bomUpdates, err = QueueMetadata(bomUpdates, MetadataUpdate{
+											Href:      workspaceHref(*href, wvm, wvmid, *wvid),
+											Fix:       fmt.Sprintf("Main part \"%v\" included in BOM", partConsolidated.Name),
+											Check:     "Main part is excluded from BOM",
+											ElementID: elementID,
+											PartID:    span.partID,
+										}, *partProps, "Exclude from BOM", false)
+										if err != nil {
+											return result, err
+										}
+									} else {
+										result.AddCheck("Main part is excluded from BOM")
+									}
 								}
+								partSpans = append(partSpans, result.endPartSpan(span))
 							}
 						}
 					} else {
@@ -379,6 +763,7 @@ func processFile(ctx context.Context, client *onshape.APIClient, parentPath stri
 					// TODO: Track consistency of the Exclude from BOM bit
 					if hasPartsItems && len(*partsItems) > 0 {
 						reportedExclude := false
+						reportedRevision := false
 						foundDoNotUse := false
 						for _, part := range *partsItems {
 							parttype, hasPartType := part.GetPartTypeOk()
@@ -388,10 +773,40 @@ func processFile(ctx context.Context, client *onshape.APIClient, parentPath stri
 								if err != nil {
 									return result, err
 								}
+								span := result.startPartSpan(part)
+								result.State.set(partConsolidated.State, "Helper")
+								if checkHelperRevisionManaged(&result, reportedRevision, partConsolidated.Name, partConsolidated.NotRevisionManaged) {
+									reportedRevision = true
+									pid, hasPid := part.GetPartIdOk()
+									href, hasHref := part.GetHrefOk()
+									if fixRevision && hasEid && hasPid && hasHref {
+										err := SetPartMetadata(ctx, client, *did, "w", *wvid, *eid, *pid, workspaceHref(*href, wvm, wvmid, *wvid), *partProps, "Not revision managed", true)
+										if err != nil {
+											return result, err
+										}
+										result.AddFix("Helper part \"%v\" set Not revision managed", partConsolidated.Name)
+									}
+								}
 
 								if !partConsolidated.ExcludeFromBOM {
+									check := ""
 									if !reportedExclude {
-										result.AddCheck("Part not excluded from BOM:\"%v\"", partConsolidated.Name)
+										check = fmt.Sprintf("Part not excluded from BOM:\"%v\"", partConsolidated.Name)
+									}
+									href, hasHref := part.GetHrefOk()
+									if fixBOM && hasHref {
+										bomUpdates, err = QueueMetadata(bomUpdates, MetadataUpdate{
+											Href:      workspaceHref(*href, wvm, wvmid, *wvid),
+											Fix:       fmt.Sprintf("Helper part \"%v\" excluded from BOM", partConsolidated.Name),
+											Check:     check,
+											ElementID: elementID,
+											PartID:    span.partID,
+										}, *partProps, "Exclude from BOM", true)
+										if err != nil {
+											return result, err
+										}
+									} else if check != "" {
+										result.AddCheck("%v", check)
 									}
 									reportedExclude = true
 								}
@@ -399,6 +814,7 @@ func processFile(ctx context.Context, client *onshape.APIClient, parentPath stri
 									strings.EqualFold(partConsolidated.Name, "DO NOT USE THESE PARTS") {
 									foundDoNotUse = true
 								}
+								partSpans = append(partSpans, result.endPartSpan(span))
 							}
 						}
 						if !foundDoNotUse {
@@ -423,32 +839,163 @@ func processFile(ctx context.Context, client *onshape.APIClient, parentPath stri
 				result.SKU.set(consolidated.SKU, "AssemblyPart#")
 				result.VendorURL.set(consolidated.Description, "AssemblyDesc")
 				result.Vendor.set(consolidated.Vendor, "Assembly")
+				mainStates = append(mainStates, consolidated.State)
+				result.State.set(consolidated.State, consolidated.Name)
+				checkMainReleased(&result, consolidated.Name, consolidated.State)
+				checkPackQuantity(&result, *documentName, consolidated)
+				checkCustomProperties(&result, consolidated.Name, consolidated, "Assembly")
 				// See if we need to fix the Vendor in this case
 				if strings.EqualFold(consolidated.Vendor, fixvendor) && consolidated.Vendor != fixvendor {
 					href, hasHref := subelement.GetHrefOk()
 					if hasHref {
-						err := SetMetadata(ctx, client, *did, "w", *wvid, *href, *properties, "Vendor", fixvendor)
+						err := SetMetadata(ctx, client, *did, "w", *wvid, workspaceHref(*href, wvm, wvmid, *wvid), *properties, "Vendor", fixvendor)
 						if err != nil {
 							fmt.Printf("SetMetadata Assembly error\n")
 							return result, err
 						}
+						result.AddFix("Assembly Vendor '%v' set to '%v'", consolidated.Vendor, fixvendor)
+						consolidated.Vendor = fixvendor
+					}
+				}
+				// Make sure that the Vendor agrees with the folder that the document is in
+				folderVendor, canFixVendor := checkFolderVendor(&result, parentPath, consolidated.Name, consolidated.Vendor)
+				if canFixVendor && fixFolderVendor {
+					href, hasHref := subelement.GetHrefOk()
+					if hasHref {
+						err := SetMetadata(ctx, client, *did, "w", *wvid, workspaceHref(*href, wvm, wvmid, *wvid), *properties, "Vendor", folderVendor)
+						if err != nil {
+							return result, err
+						}
+						result.AddFix("Assembly Vendor '%v' set to '%v'", consolidated.Vendor, folderVendor)
+					}
+				}
+				sku, canFixSKU := checkSKU(&result, auditConfig.vendorPolicy(parentPath, consolidated.Vendor), consolidated.Name, consolidated.SKU)
+				if canFixSKU && fixSKU {
+					href, hasHref := subelement.GetHrefOk()
+					if hasHref {
+						err := SetMetadata(ctx, client, *did, "w", *wvid, workspaceHref(*href, wvm, wvmid, *wvid), *properties, "Part number", sku)
+						if err != nil {
+							return result, err
+						}
+						result.AddFix("Assembly Part number '%v' set to '%v'", consolidated.SKU, sku)
+					}
+				}
+				if looksLikeURL(consolidated.Description) {
+					vendorURL, canFixURL := checkVendorURL(&result, parentPath, consolidated.Vendor, []string{sku}, "Assembly Description", consolidated.Description)
+					if canFixURL && fixURL {
+						href, hasHref := subelement.GetHrefOk()
+						if hasHref {
+							err := SetMetadata(ctx, client, *did, "w", *wvid, workspaceHref(*href, wvm, wvmid, *wvid), *properties, "Description", vendorURL)
+							if err != nil {
+								return result, err
+							}
+							result.AddFix("Assembly Description URL set to '%v'", vendorURL)
+						}
 					}
 				}
 				foundPiece = true
+				// Check the Part number and Vendor in each of the configurations if asked to
+				eid, hasEid := subelement.GetElementIdOk()
+				if maxConfigurations > 0 && hasEid {
+					err := auditConfigurations(ctx, client, &result, *did, wvm, wvmid, *eid, tabType)
+					if err != nil {
+						return result, err
+					}
+				}
 			} else {
 				// The name doesn't match, so just note it in the checks
 				result.AddCheck(" ExtraAssembly:\"%v\"", consolidated.Name)
 
 			}
 
+		case "Drawing":
+			drawings++
+			result.DrawnBy.set(consolidated.DrawnBy, "Drawing")
+			result.DateDrawn.set(formatTreeTime(consolidated.DateDrawn), "Drawing")
+			result.Revision.set(consolidated.Revision, "Drawing")
+			if auditConfig.Drawings.MatchName && !strings.EqualFold(*documentName, consolidated.Name) {
+				result.AddCheck("Drawing name \"%v\" does not match document", consolidated.Name)
+			}
+			if auditConfig.Drawings.RequireDrawnBy && consolidated.DrawnBy == "" {
+				result.AddCheck("Drawing \"%v\" missing Drawn by", consolidated.Name)
+			}
+			if auditConfig.Drawings.RequireDateDrawn && consolidated.DateDrawn.IsZero() {
+				result.AddCheck("Drawing \"%v\" missing Date drawn", consolidated.Name)
+			}
+			if auditConfig.Drawings.RequireRevision && consolidated.Revision == "" {
+				result.AddCheck("Drawing \"%v\" missing Revision", consolidated.Name)
+			}
+
 		case "BLOB":
 		default:
 			// We can ignore the tab
 		}
+		if listParts {
+			rows := []fileInfo{elementRow(&result, elementID, tabType, consolidated, checksBefore, countBefore, partSpans)}
+			if parts, hasParts := subelement.GetPartsOk(); hasParts && tabType == "Part Studio" {
+				partSubRows, err := partRows(&result, elementID, parts, partSpans)
+				if err != nil {
+					return result, err
+				}
+				rows = append(rows, partSubRows...)
+			}
+			// The tab goes ahead of any configuration rows which were added while checking it
+			result.SubRows = append(result.SubRows[:subRowsBefore], append(rows, result.SubRows[subRowsBefore:]...)...)
+		}
 
 	}
+	if parsed.URL != "" {
+		vendorURL, canFixURL := checkVendorURL(&result, parentPath, result.Vendor.result().Value, result.SKU.values(), "Description", parsed.URL)
+		if canFixURL && fixURL {
+			err = OnshapeSetDocumentDescription(ctx, client, *did, parsed.SetLine(parsed.URLLine, vendorURL))
+			if err != nil {
+				return result, err
+			}
+			result.AddFix("Description URL set to '%v'", vendorURL)
+		}
+	}
+	// Send all of the Exclude from BOM changes in one request
+	err = SetMetadataBatch(ctx, client, *did, "w", *wvid, bomUpdates)
+	annotation := " (fixed)"
+	if err != nil {
+		annotation = ""
+	} else if dryRun {
+		annotation = " (would fix)"
+	}
+	for _, update := range bomUpdates {
+		if update.Check != "" {
+			result.AddCheck("%v%v", update.Check, annotation)
+			result.addPartRowCheck(update.ElementID, update.PartID, update.Check+annotation)
+		}
+	}
+	if err != nil {
+		return result, err
+	}
+	for _, update := range bomUpdates {
+		result.AddFix("%v", update.Fix)
+	}
+	checkTabs(&result, tabs)
+	checkLifecycle(&result, parentPath, parsed, mainStates)
+	checkCatalog(&result)
+	result.checkVariants()
 	if !foundPiece {
 		result.AddCheck(" NoMainPieceFound")
+	} else if auditConfig.Drawings.Required && drawings == 0 {
+		result.AddCheck("No Drawing for main piece")
 	}
 	return result, err
 }
+
+// createFixVersion creates a version of a document once anything has been fixed so that library users get to see the changes
+func createFixVersion(ctx context.Context, client *onshape.APIClient, result *fileInfo, did string, wvid string) error {
+	if versionTemplate == "" || len(result.Fixes) == 0 {
+		return nil
+	}
+	versionName := expandVersionName(versionTemplate, result.DocumentName, time.Now())
+	err := OnshapeCreateVersion(ctx, client, did, wvid, versionName, strings.Join(result.Fixes, "\n"))
+	if err != nil {
+		return err
+	}
+	result.AddFix("Created version '%v'", versionName)
+	return nil
+}
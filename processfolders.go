@@ -18,12 +18,12 @@ func processFolders(ctx context.Context, client *onshape.APIClient, workQueue ch
 	if len(folderIDs) > 0 {
 		for _, id := range folderIDs {
 			// order++
-			folderQueue.Push(id, "Seed")
+			folderQueue.Push(id, "Seed", "", nil)
 		}
 	} else {
 		// We will start with the magic tree root for "My Onshape"
 		// order++
-		folderQueue.Push("1", "Seed")
+		folderQueue.Push("1", "Seed", "", nil)
 	}
 
 	for folderQueue.Size() > 0 {
@@ -33,23 +33,34 @@ func processFolders(ctx context.Context, client *onshape.APIClient, workQueue ch
 			break
 		}
 
-		// Put the folder entry into the output print queue so that we can get the path and the url to the path
+		// Put the folder entry into the output print queue so that we can get the path and the url to the path.
+		// We reserve the spot in the order now, but don't send it until we have traversed the folder so that
+		// we know how many documents and subfolders it has.
 		folderResult := makefileInfo()
+		folderResult.IsFolder = true
+		folderResult.FolderID = folderent.FolderID
+		folderResult.ParentID = folderent.ParentID
 		folderResult.OnshapeURL = fmt.Sprintf("https://cad.onshape.com/documents?nodeId=%v&resourceType=folder", folderent.FolderID)
 		folderResult.Path = folderent.FolderPath
+		if folderent.Node != nil {
+			folderResult.SetTreeInfo(*folderent.Node)
+		}
 		order++
-		output := doneItem{order: order, workerID: -1, err: nil, result: folderResult, finished: false}
-		doneQueue <- output
+		folderOrder := order
 
 		err = OnshapeTraverseFolder(ctx, client, folderent.FolderID,
 			func(ctx context.Context, client *onshape.APIClient, parentPath string, element onshape.BTGlobalTreeMagicNodeInfo) error {
 				order++
-				return queueFile(workQueue, order, parentPath, element)
-			}, func(ctx context.Context, client *onshape.APIClient, parentPath string, folderID string) error {
+				folderResult.Documents++
+				return queueFile(workQueue, order, parentPath, folderent.FolderID, element)
+			}, func(ctx context.Context, client *onshape.APIClient, parentPath string, folderID string, element onshape.BTGlobalTreeMagicNodeInfo) error {
 				//order++
-				folderQueue.Push(folderID, parentPath)
+				folderResult.Subfolders++
+				folderQueue.Push(folderID, parentPath, folderent.FolderID, &element)
 				return nil
 			})
+		output := doneItem{order: folderOrder, workerID: -1, err: err, result: folderResult, finished: false}
+		doneQueue <- output
 		if err != nil {
 			return order, err
 		}
@@ -0,0 +1,60 @@
+package main
+
+import "strings"
+
+// The lifecycle states a document can be in, based on the tags in its description
+const (
+	lifecycleActive       = "Active"
+	lifecycleObsolete     = "Obsolete"
+	lifecycleDiscontinued = "Discontinued"
+)
+
+// descriptionLifecycle figures out the lifecycle state from the description tags.
+// If the description didn't parse, we fall back to just looking for the tags anywhere in it
+func descriptionLifecycle(parsed ParsedDescription, description string) string {
+	if _, found := parsed.Tag("OBSOLETE"); found {
+		return lifecycleObsolete
+	}
+	if _, found := parsed.Tag("DISCONTINUED"); found {
+		return lifecycleDiscontinued
+	}
+	if parsed.Layout == "" {
+		if strings.Contains(description, "[OBSOLETE]") {
+			return lifecycleObsolete
+		}
+		if strings.Contains(description, "[DISCONTINUED]") {
+			return lifecycleDiscontinued
+		}
+	}
+	return lifecycleActive
+}
+
+// checkLifecycle makes sure that obsolete and discontinued documents have been retired properly.
+// states holds the State property of the main parts and assemblies
+func checkLifecycle(result *fileInfo, parentPath string, parsed ParsedDescription, states []string) {
+	policy := auditConfig.Lifecycle
+	inArchive := policy.ArchiveFolder != "" && pathHasPrefix(parentPath, policy.ArchiveFolder)
+	if result.Lifecycle == lifecycleActive {
+		if inArchive {
+			result.AddCheck("Active document in archive folder")
+		}
+		return
+	}
+	if policy.RequireReplacement {
+		replacement, found := parsed.Tag("REPLACED BY")
+		if !found || replacement == "" {
+			result.AddCheck("%v document has no [REPLACED BY ...]", result.Lifecycle)
+		}
+	}
+	if policy.ArchiveFolder != "" && !inArchive {
+		result.AddCheck("%v document not in %v", result.Lifecycle, policy.ArchiveFolder)
+	}
+	if policy.RequireObsoleteState {
+		for _, state := range states {
+			if !strings.EqualFold(state, "Obsolete") {
+				result.AddCheck("%v document has State '%v'", result.Lifecycle, state)
+				break
+			}
+		}
+	}
+}
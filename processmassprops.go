@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// reportMassProperties adds the mass, volume and size of a main part to the report
+func reportMassProperties(result *fileInfo, props PartMassProperties) {
+	result.Mass.set(fmt.Sprintf("%.4g kg", props.Mass), "PartMass")
+	result.Volume.set(fmt.Sprintf("%.4g cm^3", props.Volume*1e6), "PartVolume")
+	result.Size.set(fmt.Sprintf("%.1f x %.1f x %.1f mm", props.Size[0]*1000, props.Size[1]*1000, props.Size[2]*1000), "PartSize")
+}
+
+// checkMassProperties looks for mass properties that are going to cause trouble for anyone using the part
+func checkMassProperties(result *fileInfo, partName string, props PartMassProperties, material Material) {
+	policy := auditConfig.Mass
+	if !props.HasMass || props.Mass <= 0 {
+		result.AddCheck("Zero mass:\"%v\"", partName)
+	} else if policy.MaxMass > 0 && props.Mass > policy.MaxMass {
+		result.AddCheck("Mass %.4g kg too large:\"%v\"", props.Mass, partName)
+	}
+	if policy.MaxSize > 0 && props.LargestDimension() > policy.MaxSize {
+		result.AddCheck("Size %.1f mm too large:\"%v\"", props.LargestDimension()*1000, partName)
+	}
+	// Make sure that the density agrees with the material, which catches mass overrides and unit mistakes
+	density, hasDensity := material.GetProperty("DENS")
+	partDensity := props.Density()
+	if hasDensity && density.Value > 0 && partDensity > 0 &&
+		(density.Units == "" || density.Units == "kg/m^3") &&
+		math.Abs(partDensity-density.Value)/density.Value > policy.DensityTolerance {
+		result.AddCheck("Density %.0f kg/m^3 doesn't match %v (%.0f kg/m^3):\"%v\"", partDensity, material.String(), density.Value, partName)
+	}
+}
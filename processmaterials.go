@@ -0,0 +1,33 @@
+package main
+
+import "strings"
+
+// checkMaterial applies the material policy to a single main part
+func checkMaterial(result *fileInfo, vendor *VendorPolicy, partName string, material Material) {
+	if material.IsEmpty() || strings.EqualFold(material.String(), "No material") {
+		if auditConfig.Materials.Required {
+			result.AddCheck("No material:\"%v\"", partName)
+		}
+		return
+	}
+	if vendor == nil {
+		return
+	}
+	for _, rule := range vendor.Materials {
+		if rule.nameRegexp == nil || !rule.nameRegexp.MatchString(partName) || len(rule.Materials) == 0 {
+			continue
+		}
+		allowed := false
+		for _, name := range rule.Materials {
+			if strings.EqualFold(name, material.String()) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			result.AddCheck("Material '%v' not allowed for \"%v\" (expected %v)", material.String(), partName, strings.Join(rule.Materials, " or "))
+		}
+		// The first rule that matches the part decides it
+		return
+	}
+}
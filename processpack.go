@@ -0,0 +1,74 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// defaultPackPattern matches the pack size markers that vendors put on the end of names such as "- 25 Pack"
+const defaultPackPattern = `(?i)\s*-?\s*\d+\s*Pack\s*$`
+
+// defaultPackRule is used when the configuration doesn't mark any of the name rewrites as the pack rule
+var defaultPackRule = RewriteRule{Pattern: defaultPackPattern, Reason: "pack size", Pack: true, patternRegexp: regexp.MustCompile(defaultPackPattern)}
+
+// packRule finds the name rewrite marked with "pack" so that the pack checks and the name fixer always agree
+func packRule() *RewriteRule {
+	for idx := range auditConfig.NameRewrites {
+		rule := &auditConfig.NameRewrites[idx]
+		if rule.Pack && rule.patternRegexp != nil {
+			return rule
+		}
+	}
+	return &defaultPackRule
+}
+
+// stripPackSize removes any pack size marker from a name
+func stripPackSize(name string) string {
+	return packRule().patternRegexp.ReplaceAllString(name, "")
+}
+
+// packSize finds the number of items in a pack from the pack size marker on a name
+func packSize(name string) (int, bool) {
+	rule := packRule()
+	digits := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, rule.patternRegexp.FindString(name))
+	size, err := strconv.Atoi(digits)
+	if err != nil {
+		return 0, false
+	}
+	return size, true
+}
+
+// checkPackQuantity makes sure that the Unit of measure and Pack quantity agree with any pack size marker in the names.
+// The document may model a whole pack, but the BOM should still count the individual pieces
+func checkPackQuantity(result *fileInfo, documentName string, item ConsolidatedProperties) {
+	policy := auditConfig.Packs
+	size, hasMarker := packSize(documentName)
+	if !hasMarker {
+		size, hasMarker = packSize(item.Name)
+	}
+	quantity, hasQuantity := item.Custom[policy.QuantityProperty]
+	quantity = strings.TrimSpace(quantity)
+	if !hasMarker {
+		if hasQuantity && quantity != "" && quantity != "1" {
+			result.AddCheck("%v '%v' but no pack size in name:\"%v\"", policy.QuantityProperty, quantity, item.Name)
+		}
+		return
+	}
+	if policy.Unit != "" && !strings.EqualFold(item.UnitOfMeasure, policy.Unit) {
+		result.AddCheck("Unit of measure '%v' should be '%v' for a %v pack:\"%v\"", item.UnitOfMeasure, policy.Unit, size, item.Name)
+	}
+	if !hasQuantity || quantity == "" {
+		if policy.RequireQuantity {
+			result.AddCheck("Missing %v for a %v pack:\"%v\"", policy.QuantityProperty, size, item.Name)
+		}
+	} else if quantity != strconv.Itoa(size) {
+		result.AddCheck("%v '%v' does not match %v pack:\"%v\"", policy.QuantityProperty, quantity, size, item.Name)
+	}
+}
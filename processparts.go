@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/toebes/go-client/onshape"
+)
+
+// partSpan marks the findings that were added to a document while one of its parts was being checked
+type partSpan struct {
+	partID string
+	start  int // Position in Checks where the part started
+	end    int // Position in Checks where the part ended
+	count  int // Number of findings for the part
+}
+
+// startPartSpan remembers where the findings for a part start
+func (f *fileInfo) startPartSpan(part onshape.BTMetadataPartInfo) partSpan {
+	span := partSpan{start: len(f.Checks), count: f.CheckCount}
+	if pid, hasPid := part.GetPartIdOk(); hasPid {
+		span.partID = *pid
+	}
+	return span
+}
+
+// endPartSpan finishes off the findings for a part
+func (f *fileInfo) endPartSpan(span partSpan) partSpan {
+	span.end = len(f.Checks)
+	span.count = f.CheckCount - span.count
+	return span
+}
+
+// spanChecks gets the findings that are in a section of the Checks string
+func spanChecks(checks string, start int, end int) string {
+	return strings.TrimPrefix(checks[start:end], ", ")
+}
+
+// makeSubRow starts a -parts sub-row for an element or part of the document
+func makeSubRow(result *fileInfo, eid string, itemType string, consolidated ConsolidatedProperties) fileInfo {
+	row := makefileInfo()
+	row.Path = result.Path
+	row.FolderID = result.FolderID
+	row.ElementID = eid
+	row.ItemType = itemType
+	if eid != "" {
+		row.OnshapeURL = fmt.Sprintf("%v/e/%v", result.OnshapeURL, eid)
+	}
+	row.Name.set(consolidated.Name, itemType)
+	row.SKU.set(consolidated.SKU, itemType)
+	row.Vendor.set(consolidated.Vendor, itemType)
+	row.ExcludeFromBOM = strconv.FormatBool(consolidated.ExcludeFromBOM)
+	setCustomProperties(&row, consolidated, itemType)
+	return row
+}
+
+// elementRow makes the -parts sub-row for a tab of the document.
+// The findings are the ones that were added to the document while the tab was being checked,
+// less the ones which belong to the parts of the tab
+func elementRow(result *fileInfo, eid string, tabType string, consolidated ConsolidatedProperties, checksBefore int, countBefore int, spans []partSpan) fileInfo {
+	row := makeSubRow(result, eid, tabType, consolidated)
+	pieces := []string{}
+	pos := checksBefore
+	row.CheckCount = result.CheckCount - countBefore
+	for _, span := range spans {
+		pieces = append(pieces, spanChecks(result.Checks, pos, span.start))
+		pos = span.end
+		row.CheckCount -= span.count
+	}
+	pieces = append(pieces, spanChecks(result.Checks, pos, len(result.Checks)))
+	for _, piece := range pieces {
+		if piece != "" {
+			if row.Checks != "" {
+				row.Checks += ", "
+			}
+			row.Checks += piece
+		}
+	}
+	return row
+}
+
+// partRows makes the -parts sub-rows for all of the parts in a Part Studio.
+// The findings for each part are the ones found for it while checking the document
+func partRows(result *fileInfo, eid string, parts *onshape.BTMetadataPartsInfo, spans []partSpan) ([]fileInfo, error) {
+	rows := []fileInfo{}
+	if parts == nil {
+		return rows, nil
+	}
+	partsItems, hasPartsItems := parts.GetItemsOk()
+	if !hasPartsItems {
+		return rows, nil
+	}
+	for _, part := range *partsItems {
+		partProps, hasPartProps := part.GetPropertiesOk()
+		if !hasPartProps {
+			continue
+		}
+		partConsolidated, err := GetConsolidatedProperties(*partProps)
+		if err != nil {
+			return rows, err
+		}
+		partType := ""
+		if parttype, hasPartType := part.GetPartTypeOk(); hasPartType {
+			partType = *parttype
+		}
+		row := makeSubRow(result, eid, partType, partConsolidated)
+		if pid, hasPid := part.GetPartIdOk(); hasPid {
+			row.PartID = *pid
+			for _, span := range spans {
+				if span.partID == *pid {
+					row.Checks = spanChecks(result.Checks, span.start, span.end)
+					row.CheckCount = span.count
+				}
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// addPartRowCheck adds a finding to the -parts sub-row for a part once the part rows have been made.
+// This is for findings like the Exclude from BOM fixes that aren't known until the whole document has been checked
+func (f *fileInfo) addPartRowCheck(eid string, pid string, check string) {
+	for idx := range f.SubRows {
+		row := &f.SubRows[idx]
+		if row.ElementID == eid && row.PartID == pid && pid != "" {
+			row.AddCheck("%v", check)
+			return
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// propertyValue finds the value of a company property, by ID when one is given and otherwise by name
+func propertyValue(field PropertyField, item ConsolidatedProperties) (string, bool) {
+	if field.ID != "" {
+		value, found := item.CustomByID[field.ID]
+		return value, found
+	}
+	value, found := item.Custom[field.Name]
+	return value, found
+}
+
+// validPropertyValue determines if a value can be read as the type of the property
+func validPropertyValue(propertyType string, value string) bool {
+	var err error
+	switch propertyType {
+	case propertyNumber:
+		_, err = strconv.ParseFloat(value, 64)
+	case propertyInteger:
+		_, err = strconv.Atoi(value)
+	case propertyBool:
+		_, err = strconv.ParseBool(value)
+	case propertyDate:
+		_, err = time.Parse("2006-01-02", value)
+	}
+	return err == nil
+}
+
+// setCustomProperties fills in the report columns for the company properties of an item
+func setCustomProperties(result *fileInfo, item ConsolidatedProperties, context string) {
+	for _, field := range auditConfig.Properties {
+		value, found := propertyValue(field, item)
+		if found {
+			result.Properties[field.column()].set(value, context)
+		}
+	}
+}
+
+// checkCustomProperties reports the company properties of the main part or assembly and makes sure that they are filled in properly
+func checkCustomProperties(result *fileInfo, itemName string, item ConsolidatedProperties, context string) {
+	setCustomProperties(result, item, context)
+	for _, field := range auditConfig.Properties {
+		value, _ := propertyValue(field, item)
+		value = strings.TrimSpace(value)
+		if value == "" {
+			if field.Required {
+				result.AddCheck("Missing %v:\"%v\"", field.column(), itemName)
+			}
+			continue
+		}
+		if !validPropertyValue(field.Type, value) {
+			result.AddCheck("%v '%v' is not a %v:\"%v\"", field.column(), value, field.Type, itemName)
+		} else if field.patternRegexp != nil && !field.patternRegexp.MatchString(value) {
+			result.AddCheck("%v '%v' does not match '%v':\"%v\"", field.column(), value, field.Pattern, itemName)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import "strings"
+
+// checkMainReleased makes sure that the main part or assembly of a library document has been released
+func checkMainReleased(result *fileInfo, itemName string, state string) {
+	if !auditConfig.Release.RequireReleased || result.Lifecycle != lifecycleActive {
+		return
+	}
+	if !strings.EqualFold(state, "Released") {
+		if state == "" {
+			state = "none"
+		}
+		result.AddCheck("Main piece not Released:\"%v\" (State %v)", itemName, state)
+	}
+}
+
+// checkHelperRevisionManaged determines if a part in a helper PARTS studio needs to be marked Not revision managed.
+// It returns true when the fixer is allowed to change it.
+func checkHelperRevisionManaged(result *fileInfo, reported bool, itemName string, notRevisionManaged bool) bool {
+	if !auditConfig.Release.HelperNotRevisionManaged || notRevisionManaged {
+		return false
+	}
+	if !reported {
+		result.AddCheck("Helper part is revision managed:\"%v\"", itemName)
+	}
+	return true
+}
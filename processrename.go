@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/toebes/go-client/onshape"
+)
+
+// checkElementName looks at a Part Studio or Assembly tab whose name only differs from the document name by the name rewrites.
+// With -fixname the tab is renamed in the workspace, otherwise the mismatch is reported.
+// Either way it is the main tab, so the name that it should be treated as is returned
+func checkElementName(ctx context.Context, client *onshape.APIClient, result *fileInfo, did string, wvm string, wvmid string, wvid string,
+	tabType string, element onshape.BTMetadataElementInfo, properties []onshape.BTMetadataItemsProperties, name string, documentName string) (string, error) {
+	if strings.EqualFold(name, documentName) {
+		return name, nil
+	}
+	reason, canFix := canFixName(name, documentName)
+	if !canFix {
+		return name, nil
+	}
+	href, hasHref := element.GetHrefOk()
+	if fixName && hasHref {
+		err := SetMetadata(ctx, client, did, "w", wvid, workspaceHref(*href, wvm, wvmid, wvid), properties, "Name", documentName)
+		if err != nil {
+			return name, err
+		}
+		result.AddFix("%v '%v' renamed to '%v' (%v)", tabType, name, documentName, reason)
+	} else {
+		result.AddCheck("%v '%v' does not match document name (%v)", tabType, name, reason)
+	}
+	return documentName, nil
+}
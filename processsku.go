@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// normalizeSKU cleans up the common mistakes in a Part number: surrounding and embedded whitespace
+// along with the fancy dashes that get pasted in from vendor web sites
+func normalizeSKU(sku string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsSpace(r):
+			return -1
+		case r == '‐' || r == '‑' || r == '‒' || r == '–' || r == '—' || r == '−':
+			return '-'
+		}
+		return r
+	}, sku)
+}
+
+// checkSKU validates a Part number against the format for the vendor.
+// When the Part number is wrong but can be corrected, the corrected value is returned along with true
+func checkSKU(result *fileInfo, vendor *VendorPolicy, itemName string, sku string) (string, bool) {
+	if vendor == nil {
+		return sku, false
+	}
+	if strings.TrimSpace(sku) == "" {
+		result.AddCheck("Missing Part number:\"%v\"", itemName)
+		return sku, false
+	}
+	normalized := normalizeSKU(sku)
+	if vendor.skuRegexp == nil {
+		// We don't know the format, but we can at least complain about stray whitespace
+		if strings.TrimSpace(sku) != sku {
+			result.AddCheck("Part number '%v' has extra whitespace", sku)
+			return strings.TrimSpace(sku), true
+		}
+		return sku, false
+	}
+	if vendor.skuRegexp.MatchString(sku) {
+		return sku, false
+	}
+	if normalized != sku && vendor.skuRegexp.MatchString(normalized) {
+		result.AddCheck("Part number '%v' should be '%v'", sku, normalized)
+		return normalized, true
+	}
+	result.AddCheck("Part number '%v' is not a valid %v Part number", sku, vendor.Name)
+	return sku, false
+}
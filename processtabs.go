@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// tabTypeOrder is the order that the tab types are listed in the inventory
+var tabTypeOrder = []string{"Part Studio", "Assembly", "Drawing", "Feature Studio", "BLOB", "Aplication", "Table", "BOM", "UNKNOWN"}
+
+// tabInventory tracks the tabs found in a document
+type tabInventory struct {
+	counts     map[string]int
+	blobs      []string // Names of all the BLOB tabs
+	geometries []string // Names of all the Part Studio and Assembly tabs
+}
+
+// makeTabInventory creates an empty inventory
+func makeTabInventory() tabInventory {
+	return tabInventory{counts: map[string]int{}}
+}
+
+// add records a single tab in the inventory
+func (t *tabInventory) add(tabType string, name string) {
+	t.counts[tabType]++
+	switch tabType {
+	case "BLOB":
+		t.blobs = append(t.blobs, name)
+	case "Part Studio", "Assembly":
+		t.geometries = append(t.geometries, name)
+	}
+}
+
+// String generates the inventory for the report such as "Part Studio:2 Assembly:1 BLOB:1"
+func (t *tabInventory) String() string {
+	result := ""
+	extra := ""
+	for _, tabType := range tabTypeOrder {
+		count := t.counts[tabType]
+		if count > 0 {
+			result += fmt.Sprintf("%v%v:%v", extra, tabType, count)
+			extra = " "
+		}
+	}
+	return result
+}
+
+// hasExtension determines if the file name ends with any of the extensions (ignoring case)
+func hasExtension(name string, extensions []string) bool {
+	ext := filepath.Ext(name)
+	for _, allowed := range extensions {
+		if strings.EqualFold(ext, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTabs applies the TabPolicy to the non-geometry tabs in a document
+func checkTabs(result *fileInfo, tabs tabInventory) {
+	policy := auditConfig.Tabs
+	result.Tabs = tabs.String()
+	if policy.ForbidFeatureStudios && tabs.counts["Feature Studio"] > 0 {
+		result.AddCheck("Library document has %v Feature Studio(s)", tabs.counts["Feature Studio"])
+	}
+	if policy.ForbidApplications && tabs.counts["Aplication"] > 0 {
+		result.AddCheck("Library document has %v Application tab(s)", tabs.counts["Aplication"])
+	}
+	for _, blob := range tabs.blobs {
+		if len(policy.BlobExtensions) > 0 && !hasExtension(blob, policy.BlobExtensions) {
+			result.AddCheck("BLOB \"%v\" is not an approved file type", blob)
+		}
+		if policy.blobNameRegexp != nil && !policy.blobNameRegexp.MatchString(blob) {
+			result.AddCheck("BLOB \"%v\" is not an approved name", blob)
+		}
+		if policy.CheckUnconvertedImports && hasExtension(blob, policy.ImportExtensions) {
+			// An import which was translated will have a Part Studio or Assembly named after the file
+			base := strings.TrimSuffix(blob, filepath.Ext(blob))
+			converted := false
+			for _, geometry := range tabs.geometries {
+				if strings.EqualFold(geometry, base) || strings.EqualFold(geometry, blob) {
+					converted = true
+					break
+				}
+			}
+			if !converted {
+				result.AddCheck("Imported file \"%v\" was not converted", blob)
+			}
+		}
+	}
+}
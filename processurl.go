@@ -0,0 +1,134 @@
+package main
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// trackingParameter determines if a query parameter is only there to track where the link came from
+func trackingParameter(name string) bool {
+	name = strings.ToLower(name)
+	return strings.HasPrefix(name, "utm_") || name == "fbclid" || name == "gclid" || name == "mc_cid" || name == "mc_eid"
+}
+
+// hostMatchesDomain determines if the host is the domain or a subdomain of it
+func hostMatchesDomain(host string, domain string) bool {
+	host = strings.ToLower(host)
+	domain = strings.ToLower(strings.TrimPrefix(domain, "www."))
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// canonicalVendorURL generates the canonical form of a vendor product URL:
+// https, lowercase host, no tracking parameters, no fragment and no surrounding whitespace
+func canonicalVendorURL(parsed *url.URL) string {
+	canonical := *parsed
+	canonical.Scheme = "https"
+	canonical.Host = strings.ToLower(canonical.Host)
+	canonical.Fragment = ""
+	query := canonical.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if trackingParameter(key) {
+			query.Del(key)
+		}
+	}
+	canonical.RawQuery = query.Encode()
+	return canonical.String()
+}
+
+// looksLikeURL determines if a description is meant to be a link rather than just text
+func looksLikeURL(value string) bool {
+	value = strings.ToLower(strings.TrimSpace(value))
+	return strings.Contains(value, "://") || strings.HasPrefix(value, "www.")
+}
+
+// checkVendorURL validates a vendor product URL.  The domain is checked against the vendor for the folder
+// (or failing that the Vendor property) and the URL is expected to mention one of the SKUs if the vendor asks for it.
+// When the URL can be corrected, the canonical form is returned along with true
+func checkVendorURL(result *fileInfo, parentPath string, vendorName string, skus []string, source string, rawURL string) (string, bool) {
+	trimmed := strings.TrimSpace(rawURL)
+	if trimmed == "" {
+		return rawURL, false
+	}
+	missingScheme := strings.HasPrefix(strings.ToLower(trimmed), "www.")
+	if missingScheme {
+		trimmed = "https://" + trimmed
+	}
+	parsed, err := url.Parse(trimmed)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		result.AddCheck("%v URL '%v' is not a valid link", source, trimmed)
+		return rawURL, false
+	}
+	canFix := false
+	if missingScheme {
+		result.AddCheck("%v URL is missing https://", source)
+		canFix = true
+	} else if trimmed != rawURL {
+		result.AddCheck("%v URL has extra whitespace", source)
+		canFix = true
+	}
+	if parsed.Scheme != "https" {
+		result.AddCheck("%v URL is not https", source)
+		canFix = true
+	}
+	for key := range parsed.Query() {
+		if trackingParameter(key) {
+			result.AddCheck("%v URL has tracking parameters", source)
+			canFix = true
+			break
+		}
+	}
+	if parsed.Fragment != "" {
+		result.AddCheck("%v URL has fragment", source)
+		canFix = true
+	}
+	if parsed.Host != strings.ToLower(parsed.Host) {
+		result.AddCheck("%v URL host not lowercase", source)
+		canFix = true
+	}
+
+	vendor := auditConfig.vendorByFolder(parentPath)
+	if vendor == nil {
+		vendor = auditConfig.vendorByName(vendorName)
+	}
+	if vendor != nil && len(vendor.Domains) > 0 {
+		matchedDomain := false
+		for _, domain := range vendor.Domains {
+			if hostMatchesDomain(parsed.Hostname(), domain) {
+				matchedDomain = true
+				break
+			}
+		}
+		if !matchedDomain {
+			result.AddCheck("%v URL '%v' is not a %v site", source, parsed.Hostname(), vendor.Name)
+		}
+	}
+	knownSKUs := []string{}
+	for _, sku := range skus {
+		if normalizeSKU(sku) != "" {
+			knownSKUs = append(knownSKUs, normalizeSKU(sku))
+		}
+	}
+	if vendor != nil && vendor.URLContainsSKU && len(knownSKUs) > 0 {
+		lowerURL := strings.ToLower(parsed.Path + "?" + parsed.RawQuery)
+		matchedSKU := false
+		for _, sku := range knownSKUs {
+			if strings.Contains(lowerURL, strings.ToLower(sku)) {
+				matchedSKU = true
+				break
+			}
+		}
+		if !matchedSKU {
+			result.AddCheck("%v URL does not mention Part number %v", source, strings.Join(knownSKUs, "/"))
+		}
+	}
+	if !canFix {
+		return rawURL, false
+	}
+	return canonicalVendorURL(parsed), true
+}
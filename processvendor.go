@@ -0,0 +1,18 @@
+package main
+
+import "strings"
+
+// checkFolderVendor compares the Vendor property of a part or assembly with the vendor that owns the folder it is in.
+// When they disagree, the vendor name from the folder is returned along with true so that it can be fixed
+func checkFolderVendor(result *fileInfo, parentPath string, itemName string, vendor string) (string, bool) {
+	expected := auditConfig.vendorByFolder(parentPath)
+	if expected == nil || vendor == expected.Name {
+		return vendor, false
+	}
+	if strings.TrimSpace(vendor) == "" {
+		result.AddCheck("Missing Vendor:\"%v\" (folder is %v)", itemName, expected.Name)
+	} else {
+		result.AddCheck("Vendor '%v' does not match folder vendor '%v'", vendor, expected.Name)
+	}
+	return expected.Name, true
+}
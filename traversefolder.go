@@ -12,7 +12,7 @@ import (
 type OnshapeDocumentCallback func(ctx context.Context, client *onshape.APIClient, parentPath string, element onshape.BTGlobalTreeMagicNodeInfo) error
 
 // OnshapeFolderCallback is called to process a folder
-type OnshapeFolderCallback func(ctx context.Context, client *onshape.APIClient, parentPath string, fid string) error
+type OnshapeFolderCallback func(ctx context.Context, client *onshape.APIClient, parentPath string, fid string, element onshape.BTGlobalTreeMagicNodeInfo) error
 
 // OnshapeTraverseFolder traverses the folder hierarchy and performs the actions on it
 func OnshapeTraverseFolder(ctx context.Context, client *onshape.APIClient, fid string, docCallback OnshapeDocumentCallback, folderCallBack OnshapeFolderCallback) error {
@@ -62,7 +62,7 @@ func OnshapeTraverseFolder(ctx context.Context, client *onshape.APIClient, fid s
 							if hasname {
 								folderpath += " > " + *foldername
 							}
-							err := folderCallBack(ctx, client, folderpath, *id)
+							err := folderCallBack(ctx, client, folderpath, *id, element)
 							if err != nil {
 								return err
 							}
@@ -1,10 +1,14 @@
 package main
 
-import "strings"
+import (
+	"sort"
+	"strings"
+)
 
 type contextCount struct {
-	context string
-	count   int
+	contexts []string // Where each use of the value came from (these can contain commas)
+	count    int
+	order    int // When the value was first seen so that ties always come out the same way
 }
 type uniqueString map[string]contextCount
 
@@ -18,82 +22,113 @@ func (u uniqueString) set(val string, context string) {
 		oldval, found := u[valStr]
 		if !found {
 			// Not been used before, so just add it to the context
-			u[valStr] = contextCount{context: context, count: 1}
+			u[valStr] = contextCount{contexts: []string{context}, count: 1, order: len(u)}
 		} else {
 			// We saw it before, so increment the count and add us to the list of contexts for it
-			u[valStr] = contextCount{context: oldval.context + "," + context, count: oldval.count + 1}
+			u[valStr] = contextCount{contexts: append(oldval.contexts, context), count: oldval.count + 1, order: oldval.order}
 		}
 	}
 }
 
-// get() returns a proper context string
+// values returns all of the distinct values that were set in sorted order
+func (u uniqueString) values() []string {
+	result := make([]string, 0, len(u))
+	for key := range u {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// uniqueVariant is one of the distinct values given to a uniqueString
+type uniqueVariant struct {
+	Value    string
+	Count    int
+	Contexts []string // Where the value came from, in the order they were set
+	CaseOnly bool     // The value only differs from the most common value by upper/lower case
+}
+
+// uniqueResult is the structured form of everything given to a uniqueString
+type uniqueResult struct {
+	Value    string          // The most common value (blank if nothing was set)
+	Contexts []string        // Where the most common value came from
+	Variants []uniqueVariant // All of the other values, most common first
+}
+
+// result picks the most common value and orders the rest of them.
+// Ties go to the value which was set first so that the result is the same every run
+func (u uniqueString) result() uniqueResult {
+	variants := make([]uniqueVariant, 0, len(u))
+	for key, item := range u {
+		variants = append(variants, uniqueVariant{Value: key, Count: item.count, Contexts: item.contexts})
+	}
+	sort.Slice(variants, func(i, j int) bool {
+		if variants[i].Count != variants[j].Count {
+			return variants[i].Count > variants[j].Count
+		}
+		return u[variants[i].Value].order < u[variants[j].Value].order
+	})
+	result := uniqueResult{}
+	if len(variants) == 0 {
+		return result
+	}
+	result.Value = variants[0].Value
+	result.Contexts = variants[0].Contexts
+	result.Variants = variants[1:]
+	for idx := range result.Variants {
+		result.Variants[idx].CaseOnly = strings.EqualFold(result.Variants[idx].Value, result.Value)
+	}
+	return result
+}
+
+// String renders the result for the report
 // If there were no references, the string will be blank
 // If there was exactly one, then we return the actual string
 // If there was more than one then we want to know all the disagreements.
 // For example, if we were given:
-//          val        context
-//          -------    ---------
-//          goBILDA    document
-//          goBILDA    part
+//
+//	val        context
+//	-------    ---------
+//	goBILDA    document
+//	goBILDA    part
+//
 // Then the result would be "goBILDA"
 //
-//          val        context
-//          -------    ---------
-//          goBILDA    document
-//          GoBilda    part
-// Would generate "goBILDA/document ALSO[GoBilda/part]"
+//	val        context
+//	-------    ---------
+//	goBILDA    document
+//	GoBilda    part
 //
-//          val        context
-//          -------    ---------
-//          goBILDA    document
-//          goBILDA    part
-//          GoBilda    part
-// Would generate "goBILDA/document,part ALSO[GoBilda/part]"
-//          val        context
-//          -------    ---------
-//          goBILDA    document
-//          GoBilda    part
-//          GoBilda    part
-// Would generate "goBILDA/document ALSO[GoBilda/part,part]"
+// Would generate "goBILDA/document ALSO:[GoBilda:part]"
 //
-//          val        context
-//          -------    ---------
-//          goBILDA    document
-//          GoBilda    part
-//          GoBilda    part
-//          GOBILDA    assembly
-// Would generate "GoBILDA/part,part ALSO:[goBILDA/document GOBILDA/assembly]"
-
-func (u uniqueString) get() string {
-	result := ""
-	keycount := 0
-	bestmatch := ""
-	most := 0
-	// Find the most common item
-	for key, item := range u {
-		// Keep track of how many keys there were so we know if we will have any extra context
-		keycount++
-		// Is this more common than what we found (we start out with nothing)
-		if item.count > most {
-			// Yes, so save away our result and the match information for more searching
-			result = key
-			bestmatch = key
-			most = item.count
-		}
+//	val        context
+//	-------    ---------
+//	goBILDA    document
+//	goBILDA    part
+//	GoBilda    part
+//
+// Would generate "goBILDA/document,part ALSO:[GoBilda:part]"
+//
+//	val        context
+//	-------    ---------
+//	goBILDA    document
+//	GoBilda    part
+//	GoBilda    part
+//	GOBILDA    assembly
+//
+// Would generate "GoBilda/part,part ALSO:[goBILDA:document GOBILDA:assembly]"
+func (r uniqueResult) String() string {
+	if len(r.Variants) == 0 {
+		return r.Value
 	}
-	// If there was more than one then we need to append the extra information
-	if keycount > 1 {
-		extra := "/" + u[bestmatch].context + " ALSO:["
-		// Go through all the keys
-		for key, item := range u {
-			// and ignore the one that we already found
-			if key != bestmatch {
-				// Append the extra context information to what we prepared
-				result += extra + key + ":" + item.context
-				extra = " "
-			}
-		}
-		result += "]"
+	others := make([]string, 0, len(r.Variants))
+	for _, variant := range r.Variants {
+		others = append(others, variant.Value+":"+strings.Join(variant.Contexts, ","))
 	}
-	return result
+	return r.Value + "/" + strings.Join(r.Contexts, ",") + " ALSO:[" + strings.Join(others, " ") + "]"
+}
+
+// get() returns a proper context string (see uniqueResult.String)
+func (u uniqueString) get() string {
+	return u.result().String()
 }